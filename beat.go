@@ -2,10 +2,13 @@ package beat
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"regexp"
 	"runtime"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/sync/semaphore"
@@ -15,12 +18,28 @@ type JobFunc func(ctx context.Context, userdata any)
 
 type job struct {
 	Id       string  // 任务ID
+	Expr     string  // 定时表达式
 	Func     JobFunc // 定时执行的任务
 	Userdata any     // 用户数据
 
 	Schedule Schedule  // 定时时间
 	Next     time.Time // 下一次运行的时间
 	Prev     time.Time // 前一次运行的时间
+
+	runs    int          // 已执行的次数
+	maxRuns int          // 最大执行次数，0 表示不限制
+	running atomic.Int32 // 正在执行的数量
+}
+
+// 任务信息快照，修改该结构不会影响任务本身
+type JobInfo struct {
+	Id       string    // 任务ID
+	Expr     string    // 定时表达式
+	Userdata any       // 用户数据
+	Next     time.Time // 下一次运行的时间，未运行时为零值
+	Prev     time.Time // 前一次运行的时间，未运行过时为零值
+	Runs     int       // 已执行的次数
+	Running  bool      // 任务是否正在执行
 }
 
 type Beat struct {
@@ -43,11 +62,27 @@ type ScheduleParser interface {
 	Parse(expr string) (Schedule, error)
 }
 
+// 可根据任务ID解析表达式的解析器，Beat.Add 会优先使用 ParseWithID
+type ScheduleParserWithID interface {
+	ScheduleParser
+
+	// 根据任务ID解析表达式，相同的表达式和任务ID总是得到相同的定时
+	ParseWithID(expr string, id string) (Schedule, error)
+}
+
 type Schedule interface {
 	// 根据给定时间，返回下一个可用的时间
 	Next(time.Time) time.Time
 }
 
+// 可反向查询的定时
+type ReversibleSchedule interface {
+	Schedule
+
+	// 根据给定时间，返回严格早于该时间的前一个可用的时间，不存在则返回零值时间
+	Prev(time.Time) time.Time
+}
+
 // 排序需要用到的接口
 type jobByTime []*job
 
@@ -76,6 +111,11 @@ type (
 	opRemoveAll       struct{}
 	opRemoveByPattern *regexp.Regexp
 	opStop            struct{}
+	opJobs            chan []JobInfo
+	opJob             struct {
+		id     string
+		result chan *JobInfo
+	}
 )
 
 func emptyJobFunc(_ context.Context, _ any) {}
@@ -117,6 +157,7 @@ func (b *Beat) run() {
 			"job.id", job.Id,
 			"job.next", job.Next.Format(time.RFC3339))
 	}
+	b.removeExhaustedJob()
 
 	for {
 		// 对任务的下一次执行时间进行排序，
@@ -124,10 +165,11 @@ func (b *Beat) run() {
 
 		var timer *time.Timer
 		if len(b.jobs) == 0 || b.jobs[0].Next.IsZero() {
-			// 没有任务或者时间太长，则休眠，依然可以处理添加或者停止请求
+			// 没有任务或者所有任务都不会再执行，则休眠，依然可以处理添加或者停止请求
 			//
-			// 目前 parser 的最长时间为 2 年，防止休眠时间过长错过 2 年后
-			// 的任务，此处休眠时间暂定为 1 年 (8760个小时)
+			// parser 最多向后搜索 gregorianCycle (400 年)，只要有下一次执行时间，
+			// 无论多远都由下面的定时器唤醒 (超过 time.Duration 上限时提前唤醒后重新计时)，
+			// 此处没有需要等待的任务，休眠时间仅为上限，暂定为 1 年 (8760个小时)
 			timer = time.NewTimer(8760 * time.Hour)
 		} else {
 			// 获取最近执行时间的定时
@@ -148,10 +190,17 @@ func (b *Beat) run() {
 
 					b.executeJob(job)
 
+					job.runs++
 					job.Prev = job.Next
-					job.Next = job.Schedule.Next(now)
+					if job.maxRuns > 0 && job.runs >= job.maxRuns {
+						job.Next = time.Time{}
+					} else {
+						job.Next = job.Schedule.Next(now)
+					}
 				}
 
+				b.removeExhaustedJob()
+
 			case op := <-b.operate:
 				timer.Stop()
 				now = b.now()
@@ -163,6 +212,7 @@ func (b *Beat) run() {
 					newJob.Next = newJob.Schedule.Next(now)
 
 					b.addJob(newJob)
+					b.removeExhaustedJob()
 
 				case opRemove:
 					id := string(arg)
@@ -177,6 +227,12 @@ func (b *Beat) run() {
 
 					b.removeJobByPattern(pattern)
 
+				case opJobs:
+					arg <- b.jobInfos()
+
+				case opJob:
+					arg.result <- b.jobInfo(arg.id)
+
 				case opStop:
 					return
 				}
@@ -199,6 +255,7 @@ func (b *Beat) executeJob(job *job) {
 	}
 
 	b.jobWaiter.Add(1)
+	job.running.Add(1)
 
 	go func() {
 		if b.withRecovery {
@@ -218,6 +275,8 @@ func (b *Beat) executeJob(job *job) {
 			defer b.sem.Release(1)
 		}
 
+		defer job.running.Add(-1)
+
 		b.log.Debug(
 			"job.action", "execute",
 			"job.id", job.Id)
@@ -258,6 +317,23 @@ func (b *Beat) removeJob(id string) {
 	b.jobs = jobs
 }
 
+// 移除不会再执行的任务，即下一次运行时间为零值的任务，仅在运行时调用
+func (b *Beat) removeExhaustedJob() {
+	jobs := make([]*job, 0, len(b.jobs))
+
+	for _, job := range b.jobs {
+		if job.Next.IsZero() {
+			b.log.Info(
+				"job.action", "exhausted",
+				"job.id", job.Id)
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+
+	b.jobs = jobs
+}
+
 // 移除全部任务
 func (b *Beat) removeAllJob() {
 	b.log.Info("job.action", "remove-all")
@@ -295,6 +371,41 @@ func (b *Beat) find(id string) *job {
 	return nil
 }
 
+// 生成任务信息快照
+func (job *job) info() JobInfo {
+	return JobInfo{
+		Id:       job.Id,
+		Expr:     job.Expr,
+		Userdata: job.Userdata,
+		Next:     job.Next,
+		Prev:     job.Prev,
+		Runs:     job.runs,
+		Running:  job.running.Load() > 0,
+	}
+}
+
+// 获取全部任务的信息快照
+func (b *Beat) jobInfos() []JobInfo {
+	infos := make([]JobInfo, 0, len(b.jobs))
+
+	for _, job := range b.jobs {
+		infos = append(infos, job.info())
+	}
+
+	return infos
+}
+
+// 获取指定任务的信息快照，不存在则返回 nil
+func (b *Beat) jobInfo(id string) *JobInfo {
+	job := b.find(id)
+	if job == nil {
+		return nil
+	}
+
+	info := job.info()
+	return &info
+}
+
 // 添加任务
 //
 // 参数：
@@ -303,17 +414,53 @@ func (b *Beat) find(id string) *job {
 //	id: 任务ID，每个任务ID唯一
 //	fn: 任务执行回调
 //	userdata: 用于保存用户数据，回调时将传递该数据
-func (b *Beat) Add(expr string, id string, fn JobFunc, userdata any) error {
-	sched, err := b.parser.Parse(expr)
+//	opts: 任务选项，如 WithMaxRuns
+//
+// 定时不会再有有效时间 (如 Once、Bounded 的定时已结束或达到最大执行次数) 时，任务将被自动移除
+func (b *Beat) Add(expr string, id string, fn JobFunc, userdata any, opts ...jobOption) error {
+	var sched Schedule
+	var err error
+
+	if parser, ok := b.parser.(ScheduleParserWithID); ok {
+		sched, err = parser.ParseWithID(expr, id)
+	} else {
+		sched, err = b.parser.Parse(expr)
+	}
 	if err != nil {
 		return err
 	}
 
+	if st, ok := sched.(*SchedTime); ok && errors.Is(st.Validate(), ErrRareFire) {
+		b.log.Warn("msg", "schedule fires rarely", "job.id", id, "job.expr", expr)
+	}
+
+	return b.add(expr, sched, id, fn, userdata, opts)
+}
+
+// 使用已创建的定时添加任务，如 NewIntervalSchedule、Once 创建的定时，其余参数同 Add
+//
+// 定时实现 fmt.Stringer 时，JobInfo.Expr 为其生成的表达式
+func (b *Beat) AddSchedule(sched Schedule, id string, fn JobFunc, userdata any, opts ...jobOption) error {
+	if sched == nil {
+		return fmt.Errorf("%w: nil schedule", ErrInvalidExp)
+	}
+
+	expr := ""
+	if s, ok := sched.(fmt.Stringer); ok {
+		expr = s.String()
+	}
+
+	return b.add(expr, sched, id, fn, userdata, opts)
+}
+
+// 添加已解析的任务
+func (b *Beat) add(expr string, sched Schedule, id string, fn JobFunc, userdata any, opts []jobOption) error {
 	b.lock.Lock()
 	defer b.lock.Unlock()
 
 	job := &job{
 		Id:       id,
+		Expr:     expr,
 		Schedule: sched,
 		Func:     fn,
 		Userdata: userdata,
@@ -321,6 +468,9 @@ func (b *Beat) Add(expr string, id string, fn JobFunc, userdata any) error {
 	if job.Func == nil {
 		job.Func = emptyJobFunc
 	}
+	for _, opt := range opts {
+		opt(job)
+	}
 
 	if !b.running {
 		b.addJob(job)
@@ -374,6 +524,47 @@ func (b *Beat) RemoveByPattern(exp string) error {
 	return nil
 }
 
+// 获取全部任务的信息快照
+//
+// 运行时按下一次运行时间的先后顺序返回
+func (b *Beat) Jobs() []JobInfo {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if !b.running {
+		return b.jobInfos()
+	}
+
+	result := make(chan []JobInfo, 1)
+	b.operate <- opJobs(result)
+
+	return <-result
+}
+
+// 通过 ID 获取任务的信息快照
+//
+// 任务不存在时返回 ErrJobNotExist
+func (b *Beat) Job(id string) (JobInfo, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	var info *JobInfo
+
+	if !b.running {
+		info = b.jobInfo(id)
+	} else {
+		result := make(chan *JobInfo, 1)
+		b.operate <- opJob{id: id, result: result}
+		info = <-result
+	}
+
+	if info == nil {
+		return JobInfo{}, ErrJobNotExist
+	}
+
+	return *info, nil
+}
+
 // 停止运行
 func (b *Beat) Stop() {
 	b.lock.Lock()
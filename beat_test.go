@@ -44,6 +44,18 @@ func wait(wg *sync.WaitGroup) chan bool {
 	return ch
 }
 
+// Poll cond until it holds, giving up after a generous timeout.
+func eventually(cond func() bool) bool {
+	deadline := time.Now().Add(5 * OneSecond)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return cond()
+}
+
 // Start, stop, then add an job. Verify job doesn't run.
 func TestStopCausesJobsToNotRun(t *testing.T) {
 	wg := &sync.WaitGroup{}
@@ -451,3 +463,219 @@ func TestMaxGoroutines(t *testing.T) {
 		t.Fatal("expected 2 jobs to run")
 	}
 }
+
+// Query jobs before and while running.
+func TestJobs(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	beat := New()
+	beat.Add("1 1 * 0 0 0", "TestJobs-1", nil, "userdata")
+	beat.Add("* * * * * *", "TestJobs-2",
+		func(ctx context.Context, userdata any) {
+			select {
+			case started <- struct{}{}:
+				<-release
+			default:
+			}
+		},
+		nil)
+
+	jobs := beat.Jobs()
+	if len(jobs) != 2 {
+		t.Fatalf("expected 2 jobs, got %d", len(jobs))
+	}
+	for _, job := range jobs {
+		if !job.Next.IsZero() || !job.Prev.IsZero() || job.Running {
+			t.Errorf("expected idle job before running, got %+v", job)
+		}
+	}
+
+	info, err := beat.Job("TestJobs-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Expr != "1 1 * 0 0 0" || info.Userdata != "userdata" {
+		t.Errorf("unexpected job info %+v", info)
+	}
+
+	if _, err := beat.Job("TestJobs-3"); err != ErrJobNotExist {
+		t.Errorf("expected ErrJobNotExist, got %v", err)
+	}
+
+	beat.Start()
+	defer beat.Stop()
+
+	select {
+	case <-time.After(OneSecond):
+		t.Fatal("expected job runs")
+	case <-started:
+	}
+
+	jobs = beat.Jobs()
+	if len(jobs) != 2 || jobs[0].Id != "TestJobs-2" {
+		t.Fatalf("expected jobs sorted by next time, got %+v", jobs)
+	}
+
+	info, err = beat.Job("TestJobs-2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.Running || info.Prev.IsZero() || !info.Next.After(info.Prev) {
+		t.Errorf("unexpected running job info %+v", info)
+	}
+
+	close(release)
+}
+
+// Add a job with millisecond precision, expect it runs.
+// The exact firing times are covered by TestMillisecondField.
+func TestMillisecondJob(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+
+	layout := append(append([]LayoutField{}, DefaultLayout...), Millisecond)
+	beat := New(WithParser(NewParser(WithLayout(layout))))
+	beat.Add("* * * * * * */100", "TestMillisecondJob-1",
+		func(ctx context.Context, userdata any) { wg.Done() },
+		nil, WithMaxRuns(1))
+
+	beat.Start()
+	defer beat.Stop()
+
+	select {
+	case <-time.After(5 * OneSecond):
+		t.Error("expected job runs")
+	case <-wait(wg):
+	}
+}
+
+// Add a repeating job limited to 3 runs without a start time, expect exactly 3 runs.
+func TestRepeatingJobCount(t *testing.T) {
+	var calls int64
+
+	beat := New(WithParser(NewISO8601Parser()))
+	beat.Start()
+	defer beat.Stop()
+
+	err := beat.Add("R3/PT1S", "TestRepeatingJobCount-1",
+		func(ctx context.Context, userdata any) { atomic.AddInt64(&calls, 1) }, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	eventually(func() bool { return len(beat.Jobs()) == 0 })
+
+	if jobs := beat.Jobs(); len(jobs) != 0 {
+		t.Errorf("expected exhausted job removed, got %+v", jobs)
+	}
+	if n := atomic.LoadInt64(&calls); n != 3 {
+		t.Errorf("expected job runs 3 times, got %d", n)
+	}
+}
+
+type idParser struct {
+	ids []string
+}
+
+func (p *idParser) Parse(expr string) (Schedule, error) {
+	return p.ParseWithID(expr, "")
+}
+
+func (p *idParser) ParseWithID(expr string, id string) (Schedule, error) {
+	p.ids = append(p.ids, id)
+	return defaultParser.ParseWithID(expr, id)
+}
+
+// Add a job with an ID-aware parser, expect the job id passed to the parser.
+func TestAddWithIDParser(t *testing.T) {
+	parser := &idParser{}
+
+	beat := New(WithParser(parser))
+	beat.Add("* * * H H H", "TestAddWithIDParser-1", nil, nil)
+
+	if len(parser.ids) != 1 || parser.ids[0] != "TestAddWithIDParser-1" {
+		t.Errorf("expected the job id to be passed to the parser, got %v", parser.ids)
+	}
+}
+
+// Add a programmatic schedule, expect it runs and reports its expression.
+func TestAddSchedule(t *testing.T) {
+	var calls int64
+
+	sched := NewIntervalSchedule(time.Now(), 100*time.Millisecond)
+
+	beat := New()
+	if err := beat.AddSchedule(sched, "TestAddSchedule-1", func(ctx context.Context, userdata any) { atomic.AddInt64(&calls, 1) }, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := beat.AddSchedule(nil, "TestAddSchedule-2", nil, nil); err == nil {
+		t.Error("expected nil schedule to be rejected")
+	}
+
+	info, err := beat.Job("TestAddSchedule-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Expr != sched.String() {
+		t.Errorf("unexpected job expression %s", info.Expr)
+	}
+
+	// A union with a part that has no expression leaves the expression empty.
+	beat.AddSchedule(Union(Once(time.Now().Add(time.Hour)), &SchedEvery{Interval: time.Hour}), "TestAddSchedule-3", nil, nil)
+	if info, err := beat.Job("TestAddSchedule-3"); err != nil || info.Expr != "" {
+		t.Errorf("unexpected job info %+v, %v", info, err)
+	}
+
+	beat.Start()
+	defer beat.Stop()
+
+	if !eventually(func() bool { return atomic.LoadInt64(&calls) >= 2 }) {
+		t.Errorf("expected job runs at least twice, got %d", atomic.LoadInt64(&calls))
+	}
+}
+
+// Add a one-shot job and a job limited to 2 runs, expect both removed once exhausted.
+func TestExhaustedJob(t *testing.T) {
+	var once, limited int64
+
+	beat := New()
+	beat.Start()
+	defer beat.Stop()
+
+	err := beat.AddSchedule(Once(time.Now().Add(100*time.Millisecond)), "TestExhaustedJob-1",
+		func(ctx context.Context, userdata any) { atomic.AddInt64(&once, 1) }, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = beat.AddSchedule(NewIntervalSchedule(time.Now(), 100*time.Millisecond), "TestExhaustedJob-2",
+		func(ctx context.Context, userdata any) { atomic.AddInt64(&limited, 1) }, nil, WithMaxRuns(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info, err := beat.Job("TestExhaustedJob-2"); err != nil || info.Runs != 0 {
+		t.Errorf("unexpected job info %+v, %v", info, err)
+	}
+
+	eventually(func() bool {
+		return len(beat.Jobs()) == 0 && atomic.LoadInt64(&once) == 1 && atomic.LoadInt64(&limited) == 2
+	})
+
+	if jobs := beat.Jobs(); len(jobs) != 0 {
+		t.Errorf("expected exhausted jobs removed, got %+v", jobs)
+	}
+	if n := atomic.LoadInt64(&once); n != 1 {
+		t.Errorf("expected one-shot job runs once, got %d", n)
+	}
+	if n := atomic.LoadInt64(&limited); n != 2 {
+		t.Errorf("expected limited job runs twice, got %d", n)
+	}
+
+	// A one-shot job in the past never runs and is removed as soon as it is added.
+	beat.AddSchedule(Once(time.Now().Add(-time.Second)), "TestExhaustedJob-3", nil, nil)
+	if _, err := beat.Job("TestExhaustedJob-3"); err != ErrJobNotExist {
+		t.Errorf("expected past one-shot job removed, got %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package beat
+
+import "time"
+
+// 仅在指定时间执行一次的定时
+type SchedOnce struct {
+	At time.Time // 执行时间
+}
+
+// 仅在起止时间范围内有效的定时
+type SchedBounded struct {
+	Schedule Schedule  // 原始定时
+	From     time.Time // 开始时间 (含)，零值表示不限制
+	Until    time.Time // 结束时间 (含)，零值表示不限制
+}
+
+// 创建仅在 t 执行一次的定时
+func Once(t time.Time) *SchedOnce {
+	return &SchedOnce{At: t}
+}
+
+// 创建仅在 [from, until] 范围内有效的定时，from 或 until 为零值时表示不限制
+func Bounded(sched Schedule, from, until time.Time) *SchedBounded {
+	return &SchedBounded{Schedule: sched, From: from, Until: until}
+}
+
+// 获取下一个有效时间，已过执行时间则返回零值时间
+func (so *SchedOnce) Next(t time.Time) time.Time {
+	if !t.Before(so.At) {
+		return time.Time{}
+	}
+
+	return so.At.In(t.Location())
+}
+
+// 获取前一个有效时间，未到执行时间则返回零值时间
+func (so *SchedOnce) Prev(t time.Time) time.Time {
+	if !so.At.Before(t) {
+		return time.Time{}
+	}
+
+	return so.At.In(t.Location())
+}
+
+// 获取下一个有效时间，超过结束时间返回零值时间
+func (sb *SchedBounded) Next(t time.Time) time.Time {
+	if !sb.From.IsZero() && t.Before(sb.From) {
+		// 开始时间本身也是有效的
+		t = sb.From.Add(-time.Nanosecond).In(t.Location())
+	}
+
+	next := sb.Schedule.Next(t)
+	if next.IsZero() || (!sb.Until.IsZero() && next.After(sb.Until)) {
+		return time.Time{}
+	}
+
+	return next
+}
+
+// 获取前一个有效时间，早于开始时间返回零值时间，原始定时需要实现 ReversibleSchedule
+func (sb *SchedBounded) Prev(t time.Time) time.Time {
+	if !sb.Until.IsZero() && t.After(sb.Until) {
+		// 结束时间本身也是有效的
+		t = sb.Until.Add(time.Nanosecond).In(t.Location())
+	}
+
+	prev := prevOf(sb.Schedule, t)
+	if prev.IsZero() || (!sb.From.IsZero() && prev.Before(sb.From)) {
+		return time.Time{}
+	}
+
+	return prev
+}
+
+func (so *SchedOnce) describe(lang Language) string {
+	if lang == Chinese {
+		return "于 " + so.At.Format(time.RFC3339Nano) + " 执行一次"
+	}
+
+	return "Once at " + so.At.Format(time.RFC3339Nano)
+}
+
+func (sb *SchedBounded) describe(lang Language) string {
+	desc := Describe(sb.Schedule, lang)
+	if desc == "" {
+		return ""
+	}
+
+	from, until := sb.From.Format(time.RFC3339Nano), sb.Until.Format(time.RFC3339Nano)
+
+	if lang == Chinese {
+		switch {
+		case !sb.From.IsZero() && !sb.Until.IsZero():
+			return desc + "，" + from + " 至 " + until + " 期间"
+		case !sb.From.IsZero():
+			return desc + "，自 " + from + " 起"
+		case !sb.Until.IsZero():
+			return desc + "，至 " + until + " 止"
+		}
+		return desc
+	}
+
+	switch {
+	case !sb.From.IsZero() && !sb.Until.IsZero():
+		return desc + ", from " + from + " until " + until
+	case !sb.From.IsZero():
+		return desc + ", from " + from
+	case !sb.Until.IsZero():
+		return desc + ", until " + until
+	}
+	return desc
+}
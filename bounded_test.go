@@ -0,0 +1,65 @@
+package beat
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBounded(t *testing.T) {
+	// 每天 09:00
+	daily, err := defaultParser.Parse("TZ=UTC * * * 9 0 0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		sched ReversibleSchedule
+		time  string
+		next  string
+		prev  string
+	}{
+		{Once(parseTime("2026-11-01T03:00:00Z")), "2026-10-31T00:00:00Z", "2026-11-01T03:00:00Z", ""},
+		{Once(parseTime("2026-11-01T03:00:00Z")), "2026-11-01T03:00:00Z", "", ""},
+		{Once(parseTime("2026-11-01T03:00:00Z")), "2026-11-02T00:00:00Z", "", "2026-11-01T03:00:00Z"},
+		{
+			Bounded(daily, parseTime("2026-03-02T09:00:00Z"), parseTime("2026-03-04T09:00:00Z")),
+			"2026-01-01T00:00:00Z", "2026-03-02T09:00:00Z", "",
+		},
+		{
+			Bounded(daily, parseTime("2026-03-02T09:00:00Z"), parseTime("2026-03-04T09:00:00Z")),
+			"2026-03-03T12:00:00Z", "2026-03-04T09:00:00Z", "2026-03-03T09:00:00Z",
+		},
+		{
+			Bounded(daily, parseTime("2026-03-02T09:00:00Z"), parseTime("2026-03-04T09:00:00Z")),
+			"2026-03-04T09:00:00Z", "", "2026-03-03T09:00:00Z",
+		},
+		{
+			Bounded(daily, parseTime("2026-03-02T09:00:00Z"), parseTime("2026-03-04T09:00:00Z")),
+			"2026-06-01T00:00:00Z", "", "2026-03-04T09:00:00Z",
+		},
+		{Bounded(daily, parseTime("2026-03-02T10:00:00Z"), time.Time{}), "2026-03-01T00:00:00Z", "2026-03-03T09:00:00Z", ""},
+		{Bounded(daily, time.Time{}, parseTime("2026-03-02T08:00:00Z")), "2026-03-02T00:00:00Z", "", "2026-03-01T09:00:00Z"},
+	}
+
+	for _, test := range tests {
+		now := parseTime(test.time)
+
+		if actual := test.sched.Next(now); !actual.Equal(parseTime(test.next)) {
+			t.Errorf("Fail evaluating %T on %s: (expected) %s != %s (actual)", test.sched, test.time, test.next, actual)
+		}
+		if actual := test.sched.Prev(now); !actual.Equal(parseTime(test.prev)) {
+			t.Errorf("Fail reversing %T on %s: (expected) %s != %s (actual)", test.sched, test.time, test.prev, actual)
+		}
+	}
+
+	bounded := Bounded(daily, parseTime("2026-03-02T00:00:00Z"), parseTime("2026-03-31T00:00:00Z"))
+	if actual := Describe(bounded, English); actual != "At 09:00:00 (UTC), from 2026-03-02T00:00:00Z until 2026-03-31T00:00:00Z" {
+		t.Errorf("unexpected description %s", actual)
+	}
+	if actual := Describe(bounded, Chinese); actual != "每天 09:00:00（UTC），2026-03-02T00:00:00Z 至 2026-03-31T00:00:00Z 期间" {
+		t.Errorf("unexpected description %s", actual)
+	}
+	if actual := Describe(Once(parseTime("2026-11-01T03:00:00Z")), English); actual != "Once at 2026-11-01T03:00:00Z" {
+		t.Errorf("unexpected description %s", actual)
+	}
+}
@@ -0,0 +1,385 @@
+package beat
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// 日历，用于判断某天是否为假日
+type Calendar interface {
+	// 判断给定时间所在的日期是否为假日，日期使用给定时间的时区
+	IsHoliday(t time.Time) bool
+}
+
+// 由固定日期组成的日历
+type DateCalendar struct {
+	dates map[date]struct{}
+}
+
+// 按星期排除的日历，如周六和周日
+type WeeklyCalendar uint8
+
+// 多个日历的组合，任意一个日历为假日即为假日
+type MultiCalendar []Calendar
+
+// 不带时区的日期
+type date struct {
+	year  int
+	month time.Month
+	day   int
+}
+
+// 创建由固定日期组成的日历，日期使用各自时区的年月日
+func NewDateCalendar(dates ...time.Time) *DateCalendar {
+	c := &DateCalendar{dates: make(map[date]struct{})}
+	c.Add(dates...)
+
+	return c
+}
+
+// 添加假日
+func (c *DateCalendar) Add(dates ...time.Time) {
+	for _, t := range dates {
+		c.dates[dateOf(t)] = struct{}{}
+	}
+}
+
+func (c *DateCalendar) IsHoliday(t time.Time) bool {
+	_, found := c.dates[dateOf(t)]
+	return found
+}
+
+// 创建按星期排除的日历
+func NewWeeklyCalendar(days ...time.Weekday) WeeklyCalendar {
+	c := WeeklyCalendar(0)
+	for _, day := range days {
+		c |= 1 << day
+	}
+
+	return c
+}
+
+func (c WeeklyCalendar) IsHoliday(t time.Time) bool {
+	return c&(1<<t.Weekday()) != 0
+}
+
+func (c MultiCalendar) IsHoliday(t time.Time) bool {
+	for _, cal := range c {
+		if cal.IsHoliday(t) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// 从 iCalendar (.ics) 文件加载日历，参见 LoadICalendar
+func LoadICalendarFile(name string) (*DateCalendar, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return LoadICalendar(f)
+}
+
+// 从 iCalendar 数据加载日历
+//
+// 每个 VEVENT 的 DTSTART 至 DTEND (不含) 之间的日期均为假日，
+// 没有 DTEND 时仅 DTSTART 当天为假日；不支持 RRULE 等重复规则
+func LoadICalendar(r io.Reader) (*DateCalendar, error) {
+	c := NewDateCalendar()
+
+	lines, err := unfoldICalendar(r)
+	if err != nil {
+		return nil, err
+	}
+
+	inEvent := false
+	var start, end time.Time
+
+	for _, line := range lines {
+		name, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		// 去掉属性参数，如 DTSTART;VALUE=DATE
+		name, _, _ = strings.Cut(strings.ToUpper(name), ";")
+
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VEVENT"):
+			inEvent = true
+			start, end = time.Time{}, time.Time{}
+
+		case name == "END" && strings.EqualFold(value, "VEVENT"):
+			if start.IsZero() {
+				return nil, fmt.Errorf("%w: event without DTSTART", ErrInvalidCalendar)
+			}
+			if end.IsZero() || !end.After(start) {
+				end = start.AddDate(0, 0, 1)
+			}
+			for t := start; t.Before(end); t = t.AddDate(0, 0, 1) {
+				c.Add(t)
+			}
+			inEvent = false
+
+		case inEvent && (name == "DTSTART" || name == "DTEND"):
+			t, err := parseICalendarDate(value)
+			if err != nil {
+				return nil, err
+			}
+			if name == "DTSTART" {
+				start = t
+			} else {
+				end = t
+			}
+		}
+	}
+
+	return c, nil
+}
+
+// 读取 iCalendar 数据并合并折叠的行
+func unfoldICalendar(r io.Reader) ([]string, error) {
+	lines := make([]string, 0)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return lines, nil
+}
+
+// 解析 iCalendar 的日期，仅保留年月日
+func parseICalendarDate(value string) (time.Time, error) {
+	if len(value) < len("20060102") {
+		return time.Time{}, fmt.Errorf("%w: bad date %s", ErrInvalidCalendar, value)
+	}
+
+	t, err := time.Parse("20060102", value[:len("20060102")])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %s", ErrInvalidCalendar, err)
+	}
+
+	return t, nil
+}
+
+// 获取给定时间在其时区中的日期
+func dateOf(t time.Time) date {
+	year, month, day := t.Date()
+	return date{year, month, day}
+}
+
+// 遇到假日时的调整方式
+type HolidayAdjust uint8
+
+const (
+	AdjustSkip HolidayAdjust = iota // 跳过假日
+	AdjustNext                      // 顺延到下一个工作日的相同时间
+	AdjustPrev                      // 提前到前一个工作日的相同时间
+)
+
+// 仅在工作日执行的定时
+//
+// 调整方式仅对 *SchedTime 有效：若假日有执行，则在顺延或提前到的工作日按当天的时间执行，
+// 多个假日调整到同一工作日时仅执行一次；其他定时总是跳过假日
+type SchedCalendar struct {
+	Schedule Schedule      // 原始定时
+	Calendar Calendar      // 假日日历
+	Adjust   HolidayAdjust // 遇到假日时的调整方式
+}
+
+// 创建仅在工作日执行的定时
+func OnBusinessDays(sched Schedule, cal Calendar, adjust HolidayAdjust) *SchedCalendar {
+	return &SchedCalendar{Schedule: sched, Calendar: cal, Adjust: adjust}
+}
+
+// 获取下一个有效时间
+func (sc *SchedCalendar) Next(t time.Time) time.Time {
+	if st, ok := sc.Schedule.(*SchedTime); ok && sc.Adjust != AdjustSkip {
+		return sc.nextAdjusted(st, t)
+	}
+
+	next := sc.Schedule.Next(t)
+	for range combineLimit {
+		if next.IsZero() || !sc.Calendar.IsHoliday(sc.in(next)) {
+			return next
+		}
+
+		// 跳过当天剩余的时间
+		year, month, day := sc.in(next).Date()
+		nextDay := time.Date(year, month, day+1, 0, 0, 0, 0, sc.in(next).Location())
+		next = sc.Schedule.Next(nextDay.Add(-time.Nanosecond))
+	}
+
+	return time.Time{}
+}
+
+// 获取前一个有效时间，原始定时需要实现 ReversibleSchedule
+func (sc *SchedCalendar) Prev(t time.Time) time.Time {
+	if st, ok := sc.Schedule.(*SchedTime); ok && sc.Adjust != AdjustSkip {
+		return sc.prevAdjusted(st, t)
+	}
+
+	prev := prevOf(sc.Schedule, t)
+	for range combineLimit {
+		if prev.IsZero() || !sc.Calendar.IsHoliday(sc.in(prev)) {
+			return prev
+		}
+
+		// 跳过当天之前的时间
+		year, month, day := sc.in(prev).Date()
+		prev = prevOf(sc.Schedule, time.Date(year, month, day, 0, 0, 0, 0, sc.in(prev).Location()))
+	}
+
+	return time.Time{}
+}
+
+// 将时间转换为判断假日使用的时区，即 SchedTime 的时区
+func (sc *SchedCalendar) in(t time.Time) time.Time {
+	if st, ok := sc.Schedule.(*SchedTime); ok && st.location != nil && st.location != time.Local {
+		return t.In(st.location)
+	}
+
+	return t
+}
+
+// 按天向后查找有执行的工作日，再在该工作日中查找晚于 t 的时间
+func (sc *SchedCalendar) nextAdjusted(st *SchedTime, t time.Time) time.Time {
+	daily := st.daily()
+	local := sc.in(t)
+
+	year, month, day := local.Date()
+	for i := range combineLimit {
+		start := time.Date(year, month, day+i, 0, 0, 0, 0, local.Location())
+		if sc.Calendar.IsHoliday(start) || !sc.isActive(st, start) {
+			continue
+		}
+
+		from := t
+		if start.After(from) {
+			from = start.Add(-time.Nanosecond)
+		}
+
+		next := daily.Next(from)
+		if !next.IsZero() && dateOf(sc.in(next)) == dateOf(start) {
+			return next.In(t.Location())
+		}
+	}
+
+	return time.Time{}
+}
+
+// 按天向前查找有执行的工作日，再在该工作日中查找早于 t 的时间
+func (sc *SchedCalendar) prevAdjusted(st *SchedTime, t time.Time) time.Time {
+	daily := st.daily()
+	local := sc.in(t)
+
+	year, month, day := local.Date()
+	for i := range combineLimit {
+		start := time.Date(year, month, day-i, 0, 0, 0, 0, local.Location())
+		if sc.Calendar.IsHoliday(start) || !sc.isActive(st, start) {
+			continue
+		}
+
+		from := t
+		if end := start.AddDate(0, 0, 1); end.Before(from) {
+			from = end
+		}
+
+		prev := daily.Prev(from)
+		if !prev.IsZero() && dateOf(sc.in(prev)) == dateOf(start) {
+			return prev.In(t.Location())
+		}
+	}
+
+	return time.Time{}
+}
+
+// 判断工作日 day 是否需要执行，即当天有执行，
+// 或相邻的假日 (顺延时为之前的假日，提前时为之后的假日) 中有执行
+func (sc *SchedCalendar) isActive(st *SchedTime, day time.Time) bool {
+	if st.firesOn(day) {
+		return true
+	}
+
+	step := -1
+	if sc.Adjust == AdjustPrev {
+		step = 1
+	}
+
+	for i := 1; i <= combineLimit; i++ {
+		d := day.AddDate(0, 0, i*step)
+		if !sc.Calendar.IsHoliday(d) {
+			return false
+		}
+		if st.firesOn(d) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// 判断定时在 day 所在的日期是否有执行，day 为当天的开始
+func (st *SchedTime) firesOn(day time.Time) bool {
+	next := st.Next(day.Add(-time.Nanosecond))
+	return !next.IsZero() && dateOf(next.In(day.Location())) == dateOf(day)
+}
+
+// 获取每天都在相同时间执行的定时，即不限制年、月、日及星期
+func (st *SchedTime) daily() *SchedTime {
+	daily := *st
+	daily.Year = nil
+	daily.Month = Month.all()
+	daily.Dom = Dom.all()
+	daily.Dow = Dow.all()
+	daily.LastDays = 0
+	daily.LastWeekday = false
+	daily.NearestWeekday = 0
+	daily.LastDow = 0
+	daily.NthDow = 0
+
+	return &daily
+}
+
+func (sc *SchedCalendar) describe(lang Language) string {
+	desc := Describe(sc.Schedule, lang)
+	if desc == "" {
+		return ""
+	}
+
+	if _, ok := sc.Schedule.(*SchedTime); !ok || sc.Adjust == AdjustSkip {
+		if lang == Chinese {
+			return desc + "，仅工作日"
+		}
+		return desc + ", on business days only"
+	}
+
+	if lang == Chinese {
+		if sc.Adjust == AdjustPrev {
+			return desc + "，遇假日提前至前一个工作日"
+		}
+		return desc + "，遇假日顺延至下一个工作日"
+	}
+
+	if sc.Adjust == AdjustPrev {
+		return desc + ", moved to the previous business day on holidays"
+	}
+	return desc + ", moved to the next business day on holidays"
+}
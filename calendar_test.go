@@ -0,0 +1,160 @@
+package beat
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSchedCalendar(t *testing.T) {
+	weekend := NewWeeklyCalendar(time.Saturday, time.Sunday)
+	holidays := NewDateCalendar(parseTime("2024-07-01T00:00:00Z"))
+	cal := MultiCalendar{weekend, holidays}
+
+	// 每月 1 日 09:00，2024-06-01 为星期六，2024-07-01 为假日
+	monthly, err := defaultParser.Parse("TZ=UTC * 1 * 9 0 0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		adjust HolidayAdjust
+		time   string
+		next   string
+		prev   string
+	}{
+		{AdjustSkip, "2024-05-15T00:00:00Z", "2024-08-01T09:00:00Z", "2024-05-01T09:00:00Z"},
+		{AdjustNext, "2024-05-15T00:00:00Z", "2024-06-03T09:00:00Z", "2024-05-01T09:00:00Z"},
+		{AdjustNext, "2024-06-03T09:00:00Z", "2024-07-02T09:00:00Z", "2024-05-01T09:00:00Z"},
+		{AdjustPrev, "2024-05-15T00:00:00Z", "2024-05-31T09:00:00Z", "2024-05-01T09:00:00Z"},
+		{AdjustPrev, "2024-05-31T09:00:00Z", "2024-06-28T09:00:00Z", "2024-05-01T09:00:00Z"},
+	}
+
+	for _, test := range tests {
+		sched := OnBusinessDays(monthly, cal, test.adjust)
+		now := parseTime(test.time)
+
+		if actual := sched.Next(now); !actual.Equal(parseTime(test.next)) {
+			t.Errorf("Fail evaluating %d on %s: (expected) %s != %s (actual)", test.adjust, test.time, test.next, actual)
+		}
+		if actual := sched.Prev(now); !actual.Equal(parseTime(test.prev)) {
+			t.Errorf("Fail reversing %d on %s: (expected) %s != %s (actual)", test.adjust, test.time, test.prev, actual)
+		}
+	}
+
+	// 每小时执行的定时，顺延时周末的执行合并到星期一
+	hourly, err := defaultParser.Parse("TZ=UTC * * * 10-11 0 0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sched := OnBusinessDays(hourly, weekend, AdjustNext)
+	now := parseTime("2024-05-31T11:00:00Z")
+	for _, expected := range []string{"2024-06-03T10:00:00Z", "2024-06-03T11:00:00Z", "2024-06-04T10:00:00Z"} {
+		now = sched.Next(now)
+		if !now.Equal(parseTime(expected)) {
+			t.Errorf("Fail evaluating hourly schedule: (expected) %s != %s (actual)", expected, now)
+		}
+	}
+
+	// 其他定时总是跳过假日
+	every := OnBusinessDays(&SchedEvery{Interval: 12 * time.Hour}, weekend, AdjustNext)
+	if actual := every.Next(parseTime("2024-05-31T18:00:00Z")); actual.Weekday() != time.Monday {
+		t.Errorf("Fail evaluating @every schedule: %s", actual)
+	}
+}
+
+func TestLoadICalendar(t *testing.T) {
+	data := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VEVENT",
+		"SUMMARY:New Year",
+		"DTSTART;VALUE=DATE:20240101",
+		"DTEND;VALUE=DATE:2024010",
+		" 3",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"DTSTART:20240501T000000Z",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+
+	cal, err := LoadICalendar(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		time     string
+		expected bool
+	}{
+		{"2023-12-31T12:00:00Z", false},
+		{"2024-01-01T12:00:00Z", true},
+		{"2024-01-02T12:00:00Z", true},
+		{"2024-01-03T12:00:00Z", false},
+		{"2024-05-01T12:00:00Z", true},
+		{"2024-05-02T12:00:00Z", false},
+	}
+
+	for _, test := range tests {
+		if actual := cal.IsHoliday(parseTime(test.time)); actual != test.expected {
+			t.Errorf("Fail checking %s: (expected) %v != %v (actual)", test.time, test.expected, actual)
+		}
+	}
+
+	for _, data := range []string{
+		"BEGIN:VEVENT\nEND:VEVENT",
+		"BEGIN:VEVENT\nDTSTART:2024\nEND:VEVENT",
+		"BEGIN:VEVENT\nDTSTART:20241301\nEND:VEVENT",
+	} {
+		if _, err := LoadICalendar(strings.NewReader(data)); !errors.Is(err, ErrInvalidCalendar) {
+			t.Errorf("expected %q to be invalid, got %v", data, err)
+		}
+	}
+}
+
+func TestParseCalendar(t *testing.T) {
+	cal := NewWeeklyCalendar(time.Saturday, time.Sunday)
+
+	tests := []struct {
+		parser   ScheduleParser
+		spec     string
+		expected string
+	}{
+		{NewParser(WithCalendar("weekend", cal)), "TZ=UTC CAL=weekend * 1 * 9 0 0", "2024-07-01T09:00:00Z"},
+		{NewParser(WithCalendar("weekend", cal)), "CAL=weekend:next TZ=UTC * 1 * 9 0 0", "2024-06-03T09:00:00Z"},
+		{NewParser(WithCalendar("weekend", cal)), "TZ=UTC CAL=weekend:prev @monthly", "2024-05-31T00:00:00Z"},
+		{NewCrontabParser(WithCalendar("weekend", cal)), "TZ=UTC CAL=weekend:next 0 9 1 * *", "2024-06-03T09:00:00Z"},
+		{NewQuartzParser(WithCalendar("weekend", cal)), "TZ=UTC CAL=weekend:next 0 0 9 1 * ?", "2024-06-03T09:00:00Z"},
+	}
+
+	for _, test := range tests {
+		sched, err := test.parser.Parse(test.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		expected := parseTime(test.expected)
+		if actual := sched.Next(parseTime("2024-05-15T00:00:00Z")); !actual.Equal(expected) {
+			t.Errorf("Fail evaluating %s: (expected) %s != %s (actual)", test.spec, expected, actual)
+		}
+	}
+
+	sched, err := NewParser(WithCalendar("weekend", cal)).Parse("TZ=UTC CAL=weekend:next * 1 * 9 0 0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual := Describe(sched, English); actual != "At 09:00:00, on day 1 of the month (UTC), moved to the next business day on holidays" {
+		t.Errorf("unexpected description %s", actual)
+	}
+
+	for spec, offset := range map[string]int{"CAL=nowhere * * * * * *": 4, "CAL=weekend:later * * * * * *": 12} {
+		_, err := NewParser(WithCalendar("weekend", cal)).Parse(spec)
+
+		var pe *ParseError
+		if !errors.As(err, &pe) || pe.Reason != ReasonCalendar || pe.Offset != offset {
+			t.Errorf("unexpected error for %s: %v", spec, err)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package beat
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// 交集和排除查找有效时间时的最大尝试次数，超过则认为不存在
+const combineLimit = 10000
+
+// 多个定时的并集，任意一个定时的有效时间都是有效时间
+type SchedUnion struct {
+	Schedules []Schedule
+}
+
+// 两个定时的交集，两个定时共同的有效时间才是有效时间
+type SchedIntersect struct {
+	A, B Schedule
+}
+
+// 从 Base 中排除 Excluded 的有效时间
+type SchedExcept struct {
+	Base     Schedule // 基础定时
+	Excluded Schedule // 需要排除的定时
+}
+
+// 创建多个定时的并集
+func Union(a Schedule, b ...Schedule) *SchedUnion {
+	return &SchedUnion{Schedules: append([]Schedule{a}, b...)}
+}
+
+// 创建两个定时的交集
+func Intersect(a, b Schedule) *SchedIntersect {
+	return &SchedIntersect{A: a, B: b}
+}
+
+// 创建从 base 中排除 excluded 有效时间的定时
+func Except(base, excluded Schedule) *SchedExcept {
+	return &SchedExcept{Base: base, Excluded: excluded}
+}
+
+// 获取下一个有效时间，即各个定时中最早的下一个有效时间
+func (su *SchedUnion) Next(t time.Time) time.Time {
+	next := time.Time{}
+	for _, sched := range su.Schedules {
+		if n := sched.Next(t); !n.IsZero() && (next.IsZero() || n.Before(next)) {
+			next = n
+		}
+	}
+
+	return next
+}
+
+// 获取前一个有效时间，即各个定时中最晚的前一个有效时间，
+// 未实现 ReversibleSchedule 的定时将被忽略
+func (su *SchedUnion) Prev(t time.Time) time.Time {
+	prev := time.Time{}
+	for _, sched := range su.Schedules {
+		if p := prevOf(sched, t); !p.IsZero() && p.After(prev) {
+			prev = p
+		}
+	}
+
+	return prev
+}
+
+// 获取下一个有效时间，即两个定时交替向后查找直至相同
+func (si *SchedIntersect) Next(t time.Time) time.Time {
+	a, b := si.A.Next(t), si.B.Next(t)
+
+	for range combineLimit {
+		switch {
+		case a.IsZero() || b.IsZero():
+			return time.Time{}
+		case a.Equal(b):
+			return a
+		case a.Before(b):
+			a = si.A.Next(b.Add(-time.Nanosecond))
+		default:
+			b = si.B.Next(a.Add(-time.Nanosecond))
+		}
+	}
+
+	return time.Time{}
+}
+
+// 获取前一个有效时间，两个定时都需要实现 ReversibleSchedule
+func (si *SchedIntersect) Prev(t time.Time) time.Time {
+	a, b := prevOf(si.A, t), prevOf(si.B, t)
+
+	for range combineLimit {
+		switch {
+		case a.IsZero() || b.IsZero():
+			return time.Time{}
+		case a.Equal(b):
+			return a
+		case a.After(b):
+			a = prevOf(si.A, b.Add(time.Nanosecond))
+		default:
+			b = prevOf(si.B, a.Add(time.Nanosecond))
+		}
+	}
+
+	return time.Time{}
+}
+
+// 获取下一个有效时间，跳过 Excluded 的有效时间
+func (se *SchedExcept) Next(t time.Time) time.Time {
+	next := se.Base.Next(t)
+
+	for range combineLimit {
+		if next.IsZero() || !se.Excluded.Next(next.Add(-time.Nanosecond)).Equal(next) {
+			return next
+		}
+		next = se.Base.Next(next)
+	}
+
+	return time.Time{}
+}
+
+// 获取前一个有效时间，跳过 Excluded 的有效时间，Base 需要实现 ReversibleSchedule
+func (se *SchedExcept) Prev(t time.Time) time.Time {
+	prev := prevOf(se.Base, t)
+
+	for range combineLimit {
+		if prev.IsZero() || !se.Excluded.Next(prev.Add(-time.Nanosecond)).Equal(prev) {
+			return prev
+		}
+		prev = prevOf(se.Base, prev)
+	}
+
+	return time.Time{}
+}
+
+// 获取定时的前一个有效时间，未实现 ReversibleSchedule 时返回零值时间
+func prevOf(sched Schedule, t time.Time) time.Time {
+	if rs, ok := sched.(ReversibleSchedule); ok {
+		return rs.Prev(t)
+	}
+
+	return time.Time{}
+}
+
+// 解析以 | 连接的多个表达式，返回各个表达式定时的并集，
+// 解析错误的偏移为相对于整个表达式的偏移
+func parseUnion(exp string, id string, parse func(exp string, id string) (Schedule, error)) (Schedule, error) {
+	schedules := make([]Schedule, 0)
+
+	pos := 0
+	for _, part := range strings.Split(exp, "|") {
+		sched, err := parse(part, id)
+		if err != nil {
+			var pe *ParseError
+			if errors.As(err, &pe) {
+				pe.Expr = exp
+				pe.Offset += pos
+			}
+			return nil, err
+		}
+
+		schedules = append(schedules, sched)
+		pos += len(part) + 1
+	}
+
+	return Union(schedules[0], schedules[1:]...), nil
+}
+
+// 生成以 | 连接的表达式，任一定时未实现 fmt.Stringer 或无法生成表达式时返回空字符串
+func (su *SchedUnion) String() string {
+	parts := make([]string, 0, len(su.Schedules))
+	for _, sched := range su.Schedules {
+		s, ok := sched.(interface{ String() string })
+		if !ok || s.String() == "" {
+			return ""
+		}
+		parts = append(parts, s.String())
+	}
+
+	return strings.Join(parts, " | ")
+}
+
+func (su *SchedUnion) describe(lang Language) string {
+	parts := make([]string, 0, len(su.Schedules))
+	for _, sched := range su.Schedules {
+		desc := Describe(sched, lang)
+		if desc == "" {
+			return ""
+		}
+		if len(parts) > 0 && lang == English {
+			desc = lowerFirst(desc)
+		}
+		parts = append(parts, desc)
+	}
+
+	if lang == Chinese {
+		return strings.Join(parts, "；")
+	}
+	return strings.Join(parts, "; ")
+}
+
+func (si *SchedIntersect) describe(lang Language) string {
+	a, b := Describe(si.A, lang), Describe(si.B, lang)
+	if a == "" || b == "" {
+		return ""
+	}
+
+	if lang == Chinese {
+		return a + "，且" + b
+	}
+	return a + ", only when also " + lowerFirst(b)
+}
+
+func (se *SchedExcept) describe(lang Language) string {
+	base, excluded := Describe(se.Base, lang), Describe(se.Excluded, lang)
+	if base == "" || excluded == "" {
+		return ""
+	}
+
+	if lang == Chinese {
+		return base + "，但" + excluded + "除外"
+	}
+	return base + ", except " + lowerFirst(excluded)
+}
+
+// 将英文描述的首字母转换为小写，用于连接多个描述
+func lowerFirst(s string) string {
+	return strings.ToLower(s[:1]) + s[1:]
+}
@@ -0,0 +1,133 @@
+package beat
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCombinators(t *testing.T) {
+	mustParse := func(spec string) Schedule {
+		sched, err := defaultParser.Parse(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sched
+	}
+
+	// 工作日 9-17 点每 15 分钟，以及每天零点
+	business := mustParse("TZ=UTC * * 1-5 9-17 */15 0")
+	midnight := mustParse("TZ=UTC @daily")
+	lunch := mustParse("TZ=UTC * * * 12 * *")
+	hourly := mustParse("TZ=UTC @hourly")
+
+	tests := []struct {
+		sched    Schedule
+		time     string
+		expected string
+	}{
+		// 2024-03-01 为星期五
+		{Union(business, midnight), "2024-03-01T17:45:00Z", "2024-03-02T00:00:00Z"},
+		{Union(business, midnight), "2024-03-02T00:00:00Z", "2024-03-03T00:00:00Z"},
+		{Union(business, midnight), "2024-03-03T23:00:00Z", "2024-03-04T00:00:00Z"},
+		{Union(business, midnight), "2024-03-04T00:00:00Z", "2024-03-04T09:00:00Z"},
+		{Intersect(business, hourly), "2024-03-01T09:00:00Z", "2024-03-01T10:00:00Z"},
+		{Intersect(business, hourly), "2024-03-01T17:00:00Z", "2024-03-04T09:00:00Z"},
+		{Intersect(business, midnight), "2024-03-01T00:00:00Z", ""},
+		{Except(business, lunch), "2024-03-01T11:45:00Z", "2024-03-01T13:00:00Z"},
+		{Except(business, lunch), "2024-03-01T13:00:00Z", "2024-03-01T13:15:00Z"},
+		{Except(hourly, hourly), "2024-03-01T13:00:00Z", ""},
+	}
+
+	for _, test := range tests {
+		now := parseTime(test.time)
+		expected := parseTime(test.expected)
+
+		actual := test.sched.Next(now)
+		if !actual.Equal(expected) {
+			t.Errorf("Fail evaluating %T on %s: (expected) %s != %s (actual)", test.sched, test.time, expected, actual)
+			continue
+		}
+
+		if actual.IsZero() {
+			continue
+		}
+
+		if prev := test.sched.(ReversibleSchedule).Prev(actual.Add(time.Second)); !prev.Equal(actual) {
+			t.Errorf("Fail reversing %T on %s: (expected) %s != %s (actual)", test.sched, test.time, actual, prev)
+		}
+	}
+}
+
+func TestParseUnion(t *testing.T) {
+	tests := []struct {
+		parser   ScheduleParser
+		spec     string
+		time     string
+		expected string
+	}{
+		{defaultParser, "TZ=UTC * * 1-5 9-17 */15 0 | TZ=UTC @daily", "2024-03-01T17:45:00Z", "2024-03-02T00:00:00Z"},
+		{defaultParser, "TZ=UTC * * 1-5 9-17 */15 0|TZ=UTC @daily", "2024-03-01T16:50:00Z", "2024-03-01T17:00:00Z"},
+		{NewCrontabParser(), "TZ=UTC 30 9 * * 1 | TZ=UTC 0 0 1 * *", "2024-03-01T00:00:00Z", "2024-03-04T09:30:00Z"},
+		{NewQuartzParser(), "TZ=UTC 0 0 12 ? * 2 | TZ=UTC 0 0 8 L * ?", "2024-03-26T00:00:00Z", "2024-03-31T08:00:00Z"},
+		// 第一个表达式的前缀作用于所有表达式，各表达式自身的前缀优先
+		{defaultParser, "TZ=Asia/Tokyo * * * 9 0 0 | @daily", "2024-03-01T01:00:00Z", "2024-03-01T15:00:00Z"},
+		{defaultParser, "TZ=Asia/Tokyo @daily | TZ=UTC * * * 12 0 0", "2024-03-01T01:00:00Z", "2024-03-01T12:00:00Z"},
+		{NewCrontabParser(), "TZ=Asia/Tokyo 0 9 * * * | @daily", "2024-03-01T01:00:00Z", "2024-03-01T15:00:00Z"},
+		{NewQuartzParser(), "TZ=Asia/Tokyo 0 0 9 * * ? | 0 0 0 * * ?", "2024-03-01T01:00:00Z", "2024-03-01T15:00:00Z"},
+	}
+
+	for _, test := range tests {
+		sched, err := test.parser.Parse(test.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		expected := parseTime(test.expected)
+		if actual := sched.Next(parseTime(test.time)); !actual.Equal(expected) {
+			t.Errorf("Fail evaluating %s on %s: (expected) %s != %s (actual)", test.spec, test.time, expected, actual)
+		}
+	}
+
+	sched, err := defaultParser.Parse("* * * 0 0 0 | @hourly")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual := sched.(*SchedUnion).String(); actual != "* * * 0 0 0 | * * * * 0 0" {
+		t.Errorf("unexpected union expression %s", actual)
+	}
+	if actual := Describe(sched, English); actual != "At 00:00:00; at 00:00 past the hour" {
+		t.Errorf("unexpected union description %s", actual)
+	}
+	if actual := Describe(sched, Chinese); actual != "每天 00:00:00；每小时的00分00秒" {
+		t.Errorf("unexpected union description %s", actual)
+	}
+
+	sched, err = defaultParser.Parse("TZ=Asia/Tokyo * * * 9 0 0 | @daily")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual := sched.(*SchedUnion).String(); actual != "TZ=Asia/Tokyo * * * 9 0 0 | TZ=Asia/Tokyo * * * 0 0 0" {
+		t.Errorf("unexpected union expression %s", actual)
+	}
+
+	// 部分定时无法生成表达式时不返回不完整的表达式
+	if actual := Union(Once(time.Now()), &SchedEvery{Interval: time.Hour}).String(); actual != "" {
+		t.Errorf("unexpected union expression %s", actual)
+	}
+	if actual := Union(&SchedEvery{Interval: time.Hour}, Union(Once(time.Now()))).String(); actual != "" {
+		t.Errorf("unexpected union expression %s", actual)
+	}
+
+	var pe *ParseError
+	_, err = defaultParser.Parse("TZ=Nowhere/City * * * 9 0 0 | @daily")
+	if !errors.As(err, &pe) || pe.Reason != ReasonLocation || pe.Offset != 3 {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	_, err = defaultParser.Parse("@daily | * * * * 60 0")
+	if !errors.As(err, &pe) || pe.Offset != 17 || pe.Expr != "@daily | * * * * 60 0" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
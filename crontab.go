@@ -0,0 +1,84 @@
+package beat
+
+import (
+	"slices"
+	"strings"
+)
+
+var (
+	crontabLayout        = []LayoutField{Minute, Hour, Dom, Month, Dow}
+	crontabSecondsLayout = []LayoutField{Second, Minute, Hour, Dom, Month, Dow}
+)
+
+// 兼容 Unix crontab 的解析器
+//
+// 支持以下形式的表达式：
+//
+//	[minute] [hour] [dom] [month] [dow]
+//	[second] [minute] [hour] [dom] [month] [dow]
+//
+// 星期使用 0-7 表示，0 和 7 都表示星期天；月份和星期支持英文缩写，
+// 同时支持 TZ= 前缀以及 @daily 等预定义表达式。
+// 与 Vixie cron 相同，“日”和“星期”都不以 * 开头时，任意一个匹配即认为匹配，
+// 如 0 0 1,15 * 5 表示 1 日、15 日或星期五，而 0 0 */2 * 1 表示单数日且为星期一。
+// 多个表达式可以使用 | 连接，得到各个定时的并集。
+type CrontabParser struct {
+	parser        *Parser // 5 个域的解析器
+	secondsParser *Parser // 带秒的 6 个域的解析器
+}
+
+// 创建 crontab 解析器，opts 中的 WithLayout 将被忽略
+func NewCrontabParser(opts ...parserOption) *CrontabParser {
+	opts = slices.Clip(append([]parserOption{
+		WithDayMatch(DayMatchOr),
+		withDowNumbering(dowCrontab),
+	}, opts...))
+
+	return &CrontabParser{
+		parser:        NewParser(append(opts, WithLayout(crontabLayout))...),
+		secondsParser: NewParser(append(opts, WithLayout(crontabSecondsLayout))...),
+	}
+}
+
+// 解析 crontab 表达式
+func (p *CrontabParser) Parse(exp string) (Schedule, error) {
+	return p.ParseWithID(exp, "")
+}
+
+// 根据任务ID解析 crontab 表达式，参见 Parser.ParseWithID
+func (p *CrontabParser) ParseWithID(exp string, id string) (Schedule, error) {
+	if strings.Contains(exp, "|") {
+		prefix, err := p.parser.unionPrefix(exp)
+		if err != nil {
+			return nil, err
+		}
+
+		return parseUnion(exp, id, func(part string, id string) (Schedule, error) {
+			return p.parse(part, id, prefix)
+		})
+	}
+
+	return p.parse(exp, id, p.parser.defaultPrefix())
+}
+
+// 解析不含 | 的单个 crontab 表达式，prefix 为表达式未指定前缀时使用的时区和日历
+func (p *CrontabParser) parse(exp string, id string, prefix exprPrefix) (Schedule, error) {
+	fields := strings.Fields(exp)
+	for len(fields) > 0 && isPrefixField(fields[0]) {
+		fields = fields[1:]
+	}
+
+	if len(fields) > 0 && strings.HasPrefix(fields[0], "@") {
+		return p.parser.parse(exp, id, prefix)
+	}
+
+	switch len(fields) {
+	case len(crontabLayout):
+		return p.parser.parse(exp, id, prefix)
+
+	case len(crontabSecondsLayout):
+		return p.secondsParser.parse(exp, id, prefix)
+	}
+
+	return nil, locateError(newParseError(ReasonFieldCount, len(exp), "invalid number of fields"), exp, 0, -1, 0)
+}
@@ -0,0 +1,95 @@
+package beat
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCrontabParser(t *testing.T) {
+	tests := []struct {
+		spec     string
+		start    string
+		expected []string
+	}{
+		// Classic field order, seconds default to 0.
+		{"30 9 * * *", "2024-07-09T10:00:00Z", []string{
+			"2024-07-10T09:30:00Z", "2024-07-11T09:30:00Z",
+		}},
+		// Optional seconds field.
+		{"15 30 9 * * *", "2024-07-09T10:00:00Z", []string{
+			"2024-07-10T09:30:15Z",
+		}},
+		// 0 and 7 are both Sunday.
+		{"0 0 * * 0", "2024-07-09T00:00:00Z", []string{
+			"2024-07-14T00:00:00Z", "2024-07-21T00:00:00Z",
+		}},
+		{"0 0 * * 7", "2024-07-09T00:00:00Z", []string{
+			"2024-07-14T00:00:00Z",
+		}},
+		// Sunday through Tuesday.
+		{"0 0 * * 0-2", "2024-07-12T00:00:00Z", []string{
+			"2024-07-14T00:00:00Z", "2024-07-15T00:00:00Z", "2024-07-16T00:00:00Z", "2024-07-21T00:00:00Z",
+		}},
+		{"0 0 * * sun-tue", "2024-07-12T00:00:00Z", []string{
+			"2024-07-14T00:00:00Z", "2024-07-15T00:00:00Z", "2024-07-16T00:00:00Z", "2024-07-21T00:00:00Z",
+		}},
+		// Both day fields restricted: the 1st, the 15th or any Friday.
+		{"0 0 1,15 * 5", "2024-07-09T00:00:00Z", []string{
+			"2024-07-12T00:00:00Z", "2024-07-15T00:00:00Z", "2024-07-19T00:00:00Z",
+		}},
+		// A field starting with * keeps AND semantics, as in Vixie cron: odd days that are Mondays.
+		{"0 0 */2 * 1", "2024-07-09T00:00:00Z", []string{
+			"2024-07-15T00:00:00Z", "2024-07-29T00:00:00Z", "2024-08-05T00:00:00Z",
+		}},
+		// The 1st or the 15th falling on Sunday, Tuesday, Thursday or Saturday.
+		{"0 0 1,15 * */2", "2024-07-09T00:00:00Z", []string{
+			"2024-08-01T00:00:00Z", "2024-08-15T00:00:00Z", "2024-09-01T00:00:00Z",
+		}},
+		// Without a leading *, a full range still means OR: any day of the month or Monday.
+		{"0 0 1-31 * 1", "2024-07-09T00:00:00Z", []string{
+			"2024-07-10T00:00:00Z", "2024-07-11T00:00:00Z",
+		}},
+		// Names and descriptors.
+		{"0 12 * JAN,jul MON-FRI", "2024-07-09T13:00:00Z", []string{
+			"2024-07-10T12:00:00Z",
+		}},
+		{"@weekly", "2024-07-09T00:00:00Z", []string{
+			"2024-07-14T00:00:00Z",
+		}},
+		{"TZ=Asia/Shanghai */30 1 * * *", "2024-07-09T00:00:00Z", []string{
+			"2024-07-09T17:00:00Z", "2024-07-09T17:30:00Z",
+		}},
+	}
+
+	parser := NewCrontabParser(WithDefaultLocation(time.UTC))
+
+	for _, test := range tests {
+		sched, err := parser.Parse(test.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		next := parseTime(test.start)
+		for _, item := range test.expected {
+			actual := sched.Next(next)
+			expected := parseTime(item)
+			if !actual.Equal(expected) {
+				t.Errorf("Fail evaluating %s on %s: (expected) %s != %s (actual)",
+					test.spec, next, expected, actual)
+				break
+			}
+
+			next = actual
+		}
+	}
+
+	invalids := []string{"* * * *", "* * * * * * *", "0 0 * * 8", "60 * * * *", "0 24 * * *", "0 0 0 * *"}
+
+	for _, spec := range invalids {
+		if _, err := parser.Parse(spec); !errors.Is(err, ErrInvalidExp) {
+			t.Errorf("expected %s to be invalid, got %v", spec, err)
+		}
+	}
+}
@@ -0,0 +1,467 @@
+package beat
+
+import (
+	"fmt"
+	"math/bits"
+	"strings"
+	"time"
+)
+
+// 描述使用的语言
+type Language uint8
+
+const (
+	English Language = iota // 英文
+	Chinese                 // 中文
+)
+
+// 可生成描述的定时
+type describer interface {
+	describe(lang Language) string
+}
+
+var (
+	chineseMonths   = []string{"", "一月", "二月", "三月", "四月", "五月", "六月", "七月", "八月", "九月", "十月", "十一月", "十二月"}
+	chineseWeekdays = []string{"", "周一", "周二", "周三", "周四", "周五", "周六", "周日"}
+	englishOrdinals = []string{"", "first", "second", "third", "fourth", "fifth"}
+	chineseOrdinals = []string{"", "第一个", "第二个", "第三个", "第四个", "第五个"}
+)
+
+// 生成定时的描述，如 "At 09:00:00, Monday through Friday, in January"
+// 或 "一月的周一至周五 09:00:00"，不支持的定时返回空字符串
+func Describe(sched Schedule, lang Language) string {
+	if d, ok := sched.(describer); ok {
+		return d.describe(lang)
+	}
+
+	return ""
+}
+
+func (se *SchedEvery) describe(lang Language) string {
+	if lang == Chinese {
+		return "每隔 " + se.Interval.String()
+	}
+
+	return "Every " + se.Interval.String()
+}
+
+func (st *SchedTime) describe(lang Language) string {
+	clock, single := st.describeClock(lang)
+
+	if lang == Chinese {
+		parts := make([]string, 0, 3)
+		if len(st.Year) > 0 {
+			parts = append(parts, joinChinese(describeValues(st.Year, Year.valueRange(), func(v int) string {
+				return fmt.Sprintf("%d年", v)
+			}, lang)))
+		}
+		if date := st.describeDateChinese(single); date != "" {
+			parts = append(parts, date)
+		}
+		parts = append(parts, clock)
+
+		desc := strings.Join(parts, " ")
+		if st.location != nil && st.location != time.Local {
+			desc += "（" + st.location.String() + "）"
+		}
+		return desc
+	}
+
+	parts := []string{clock}
+	if dom := st.describeDomEnglish(); dom != "" {
+		parts = append(parts, dom)
+	}
+	if dow := st.describeDowEnglish(); dow != "" {
+		if len(parts) > 1 {
+			if st.isDayOr() {
+				dow = "or " + dow
+			} else {
+				dow = "if it is " + dow
+			}
+		}
+		parts = append(parts, dow)
+	}
+	if st.Month != Month.all() {
+		parts = append(parts, "in "+joinEnglish(describeValues(bitValues(st.Month), Month.valueRange(), func(v int) string {
+			return time.Month(v).String()
+		}, lang)))
+	}
+	if len(st.Year) > 0 {
+		parts = append(parts, "in "+joinEnglish(describeValues(st.Year, Year.valueRange(), func(v int) string {
+			return fmt.Sprint(v)
+		}, lang)))
+	}
+
+	desc := strings.Join(parts, ", ")
+	desc = strings.ToUpper(desc[:1]) + desc[1:]
+	if st.location != nil && st.location != time.Local {
+		desc += " (" + st.location.String() + ")"
+	}
+	return desc
+}
+
+// 生成时、分、秒及毫秒的描述，single 表示每天仅有一个时间
+func (st *SchedTime) describeClock(lang Language) (string, bool) {
+	hour, minute, second := singleBit(st.Hour), singleBit(st.Minute), singleBit(st.Second)
+	millis := ""
+	if len(st.Millisecond) > 0 {
+		millis = describeUnit(st.Millisecond, Millisecond.valueRange(), "millisecond", "毫秒", lang)
+	}
+
+	if hour >= 0 && minute >= 0 && second >= 0 {
+		clock := fmt.Sprintf("%02d:%02d:%02d", hour, minute, second)
+		switch {
+		case millis != "" && lang == Chinese:
+			return clock + " " + millis, false
+		case millis != "":
+			return "at " + clock + ", " + millis, false
+		case lang == Chinese:
+			return clock, true
+		default:
+			return "at " + clock, true
+		}
+	}
+
+	parts := make([]string, 0, 4)
+
+	if minute >= 0 && second >= 0 {
+		// 仅有时不固定，如每小时的 30:00
+		hours := ""
+		if st.Hour != Hour.all() {
+			hours = describeUnit(bitValues(st.Hour), Hour.valueRange(), "hour", "点", lang)
+		}
+
+		if lang == Chinese {
+			if hours == "" || strings.HasPrefix(hours, "每") {
+				hours = strings.TrimPrefix(hours, "每")
+				parts = append(parts, fmt.Sprintf("每%s的%02d分%02d秒", orDefault(hours, "小时"), minute, second))
+			} else if strings.Contains(hours, "至") {
+				parts = append(parts, fmt.Sprintf("%s每小时的%02d分%02d秒", hours, minute, second))
+			} else {
+				parts = append(parts, fmt.Sprintf("%s的%02d分%02d秒", hours, minute, second))
+			}
+		} else {
+			parts = append(parts, fmt.Sprintf("at %02d:%02d past the hour", minute, second))
+			if hours != "" {
+				parts = append(parts, hours)
+			}
+		}
+	} else {
+		// 由小到大描述各个域，较大的域不限制时已由较小的域隐含，省略
+		fields := []struct {
+			bits   uint64
+			lf     LayoutField
+			en, zh string
+		}{
+			{st.Second, Second, "second", "秒"},
+			{st.Minute, Minute, "minute", "分"},
+			{st.Hour, Hour, "hour", "点"},
+		}
+
+		for i, f := range fields {
+			// 秒为 0 且分不固定时，“每分钟”已经隐含了 0 秒；分为 0 时秒不固定，不能省略
+			if i == 0 && f.bits == 1 && minute < 0 && millis == "" {
+				continue
+			}
+
+			if f.bits == f.lf.all() && len(parts) > 0 {
+				continue
+			}
+
+			desc := describeUnit(bitValues(f.bits), f.lf.valueRange(), f.en, f.zh, lang)
+			if f.lf == Minute && second < 0 && strings.HasPrefix(desc, "at ") {
+				// 秒不固定时，分表示执行的时段
+				desc = "during " + strings.TrimPrefix(desc, "at ")
+			}
+			parts = append(parts, desc)
+		}
+
+		if lang == Chinese {
+			// 中文由大到小排列
+			for i, j := 0, len(parts)-1; i < j; i, j = i+1, j-1 {
+				parts[i], parts[j] = parts[j], parts[i]
+			}
+		}
+	}
+
+	if millis != "" {
+		if lang == Chinese {
+			parts = append(parts, millis)
+		} else {
+			parts = append([]string{millis}, parts...)
+		}
+	}
+
+	if lang == Chinese {
+		return strings.Join(parts, "的"), false
+	}
+	return strings.Join(parts, ", "), false
+}
+
+// 生成“日”域的英文描述
+func (st *SchedTime) describeDomEnglish() string {
+	if st.Dom == Dom.all() {
+		return ""
+	}
+
+	parts := make([]string, 0)
+	if st.Dom != 0 {
+		values := bitValues(st.Dom)
+		noun := "day"
+		if len(values) > 1 {
+			noun = "days"
+		}
+		parts = append(parts, noun+" "+joinEnglish(describeValues(values, Dom.valueRange(), func(v int) string {
+			return fmt.Sprint(v)
+		}, English)))
+	}
+	for n := range 31 {
+		if (1<<n)&st.LastDays == 0 {
+			continue
+		}
+		if n == 0 {
+			parts = append(parts, "the last day")
+		} else {
+			parts = append(parts, fmt.Sprintf("%d days before the last day", n))
+		}
+	}
+	if st.LastWeekday {
+		parts = append(parts, "the last weekday")
+	}
+	for n := 1; n <= 31; n++ {
+		if (1<<n)&st.NearestWeekday != 0 {
+			parts = append(parts, fmt.Sprintf("the weekday nearest day %d", n))
+		}
+	}
+
+	return "on " + joinEnglish(parts) + " of the month"
+}
+
+// 生成“星期”域的英文描述
+func (st *SchedTime) describeDowEnglish() string {
+	if st.Dow == Dow.all() {
+		return ""
+	}
+
+	parts := make([]string, 0)
+	if st.Dow != 0 {
+		parts = append(parts, joinEnglish(describeValues(bitValues(st.Dow), Dow.valueRange(), englishWeekday, English)))
+	}
+	for dow := 1; dow <= 7; dow++ {
+		if (1<<dow)&st.LastDow != 0 {
+			parts = append(parts, "on the last "+englishWeekday(dow)+" of the month")
+		}
+	}
+	for k := 1; k <= 5; k++ {
+		for dow := 1; dow <= 7; dow++ {
+			if (1<<(k*8+dow))&st.NthDow != 0 {
+				parts = append(parts, "on the "+englishOrdinals[k]+" "+englishWeekday(dow)+" of the month")
+			}
+		}
+	}
+
+	return joinEnglish(parts)
+}
+
+// 生成中文的日期描述，包括月、日及星期
+func (st *SchedTime) describeDateChinese(single bool) string {
+	month := ""
+	if st.Month != Month.all() {
+		month = joinChinese(describeValues(bitValues(st.Month), Month.valueRange(), func(v int) string {
+			return chineseMonths[v]
+		}, Chinese)) + "的"
+	}
+
+	dom := ""
+	if st.Dom != Dom.all() {
+		parts := make([]string, 0)
+		if st.Dom != 0 {
+			parts = append(parts, joinChinese(describeValues(bitValues(st.Dom), Dom.valueRange(), func(v int) string {
+				return fmt.Sprintf("%d日", v)
+			}, Chinese)))
+		}
+		for n := range 31 {
+			if (1<<n)&st.LastDays == 0 {
+				continue
+			}
+			if n == 0 {
+				parts = append(parts, "最后一天")
+			} else {
+				parts = append(parts, fmt.Sprintf("倒数第%d天", n+1))
+			}
+		}
+		if st.LastWeekday {
+			parts = append(parts, "最后一个工作日")
+		}
+		for n := 1; n <= 31; n++ {
+			if (1<<n)&st.NearestWeekday != 0 {
+				parts = append(parts, fmt.Sprintf("距离%d日最近的工作日", n))
+			}
+		}
+		dom = joinChinese(parts)
+	}
+
+	dow := ""
+	if st.Dow != Dow.all() {
+		parts := make([]string, 0)
+		if st.Dow != 0 {
+			parts = append(parts, joinChinese(describeValues(bitValues(st.Dow), Dow.valueRange(), func(v int) string {
+				return chineseWeekdays[v]
+			}, Chinese)))
+		}
+		for d := 1; d <= 7; d++ {
+			if (1<<d)&st.LastDow != 0 {
+				parts = append(parts, "最后一个"+chineseWeekdays[d])
+			}
+		}
+		for k := 1; k <= 5; k++ {
+			for d := 1; d <= 7; d++ {
+				if (1<<(k*8+d))&st.NthDow != 0 {
+					parts = append(parts, chineseOrdinals[k]+chineseWeekdays[d])
+				}
+			}
+		}
+		dow = joinChinese(parts)
+	}
+
+	var day string
+	switch {
+	case dom != "" && dow != "" && st.isDayOr():
+		day = dom + "或" + dow
+	case dom != "" && dow != "":
+		day = dom + "且为" + dow
+	case dom != "":
+		day = dom
+	case dow != "":
+		day = dow
+	}
+
+	switch {
+	case month != "" && day != "":
+		return month + day
+	case month != "":
+		return month + "每天"
+	case day != "" && dom != "":
+		return "每月" + day
+	case day != "" && st.LastDow == 0 && st.NthDow == 0:
+		return "每" + day
+	case day != "":
+		return "每月" + day
+	case single:
+		return "每天"
+	}
+
+	return ""
+}
+
+// 生成时间单位的描述，如 every 15 seconds、每15秒、hours 9 through 17、9点至17点
+func describeUnit(values []int, vr valueRange, en, zh string, lang Language) string {
+	if len(values) == vr.max-vr.min+1 {
+		if lang == Chinese {
+			return "每" + chineseUnit(zh)
+		}
+		return "every " + en
+	}
+
+	if step, ok := commonStep(values); ok && step > 1 && values[0] == vr.min && values[len(values)-1]+step > vr.max {
+		if lang == Chinese {
+			return fmt.Sprintf("每%d%s", step, chineseUnit(zh))
+		}
+		return fmt.Sprintf("every %d %ss", step, en)
+	}
+
+	if lang == Chinese {
+		return joinChinese(describeValues(values, vr, func(v int) string {
+			return fmt.Sprintf("%d%s", v, zh)
+		}, lang))
+	}
+
+	noun := en
+	if len(values) > 1 {
+		noun += "s"
+	}
+	return "at " + noun + " " + joinEnglish(describeValues(values, vr, func(v int) string {
+		return fmt.Sprint(v)
+	}, lang))
+}
+
+// 获取中文的时间单位，用于“每…”
+func chineseUnit(zh string) string {
+	switch zh {
+	case "点":
+		return "小时"
+	case "分":
+		return "分钟"
+	}
+
+	return zh
+}
+
+// 将升序排列的值描述为列表，连续 3 个及以上的值合并为范围
+func describeValues(values []int, vr valueRange, name func(int) string, lang Language) []string {
+	through := " through "
+	if lang == Chinese {
+		through = "至"
+	}
+
+	parts := make([]string, 0)
+	for i := 0; i < len(values); {
+		j := i
+		for j+1 < len(values) && values[j+1] == values[j]+1 {
+			j++
+		}
+
+		if j-i >= 2 {
+			parts = append(parts, name(values[i])+through+name(values[j]))
+		} else {
+			for k := i; k <= j; k++ {
+				parts = append(parts, name(values[k]))
+			}
+		}
+
+		i = j + 1
+	}
+
+	return parts
+}
+
+// 使用英文连接列表，如 a, b and c
+func joinEnglish(parts []string) string {
+	if len(parts) <= 1 {
+		return strings.Join(parts, "")
+	}
+
+	return strings.Join(parts[:len(parts)-1], ", ") + " and " + parts[len(parts)-1]
+}
+
+// 使用中文连接列表，如 a、b和c
+func joinChinese(parts []string) string {
+	if len(parts) <= 1 {
+		return strings.Join(parts, "")
+	}
+
+	return strings.Join(parts[:len(parts)-1], "、") + "和" + parts[len(parts)-1]
+}
+
+// 获取 ISO 8601 星期的英文名称
+func englishWeekday(dow int) string {
+	return time.Weekday(dow % 7).String()
+}
+
+// 获取位图中唯一的有效值，不唯一时返回 -1
+func singleBit(b uint64) int {
+	if bits.OnesCount64(b) != 1 {
+		return -1
+	}
+
+	return bits.TrailingZeros64(b)
+}
+
+// 字符串为空时返回缺省值
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+
+	return s
+}
@@ -0,0 +1,94 @@
+package beat
+
+import (
+	"testing"
+)
+
+func TestDescribe(t *testing.T) {
+	yearLayout := append(append([]LayoutField{}, DefaultLayout...), Year, Millisecond)
+
+	tests := []struct {
+		parser  ScheduleParser
+		spec    string
+		english string
+		chinese string
+	}{
+		{defaultParser, "1 * 1-5 9 0 0", "At 09:00:00, Monday through Friday, in January", "一月的周一至周五 09:00:00"},
+		{defaultParser, "* * * * * *", "Every second", "每秒"},
+		{defaultParser, "* * * * * 0", "Every minute", "每分钟"},
+		{defaultParser, "* * * * */15 0", "Every 15 minutes", "每15分钟"},
+		{defaultParser, "* * * * 30 0", "At 30:00 past the hour", "每小时的30分00秒"},
+		{defaultParser, "* * * */2 0 0", "At 00:00 past the hour, every 2 hours", "每2小时的00分00秒"},
+		{defaultParser, "* * * 9-17 0 0", "At 00:00 past the hour, at hours 9 through 17", "9点至17点每小时的00分00秒"},
+		{defaultParser, "* * * 9,12 0 0", "At 00:00 past the hour, at hours 9 and 12", "9点和12点的00分00秒"},
+		{defaultParser, "* * * 9-17 */15 0", "Every 15 minutes, at hours 9 through 17", "9点至17点的每15分钟"},
+		{defaultParser, "* * * * 0,30 10", "At second 10, at minutes 0 and 30", "0分和30分的10秒"},
+		{defaultParser, "* * * * 0 *", "Every second, during minute 0", "0分的每秒"},
+		{defaultParser, "* * * * 0 */10", "Every 10 seconds, during minute 0", "0分的每10秒"},
+		{defaultParser, "@daily", "At 00:00:00", "每天 00:00:00"},
+		{defaultParser, "TZ=Asia/Shanghai @daily", "At 00:00:00 (Asia/Shanghai)", "每天 00:00:00（Asia/Shanghai）"},
+		{
+			defaultParser, "* L,L-2,LW,15W ? 0 0 0",
+			"At 00:00:00, on the last day, 2 days before the last day, the last weekday and the weekday nearest day 15 of the month",
+			"每月最后一天、倒数第3天、最后一个工作日和距离15日最近的工作日 00:00:00",
+		},
+		{
+			defaultParser, "* * 5L,2#2 8 0 0",
+			"At 08:00:00, on the last Friday of the month and on the second Tuesday of the month",
+			"每月最后一个周五和第二个周二 08:00:00",
+		},
+		{
+			defaultParser, "JAN-MAR,jun 1,15 MON 9 30 0",
+			"At 09:30:00, on days 1 and 15 of the month, if it is Monday, in January through March and June",
+			"一月至三月和六月的1日和15日且为周一 09:30:00",
+		},
+		{NewCrontabParser(), "0 9 1 * 1", "At 09:00:00, on day 1 of the month, or Monday", "每月1日或周一 09:00:00"},
+		{
+			NewParser(WithLayout(yearLayout)), "* * * * * 0 2026-2030/2 */250",
+			"Every 250 milliseconds, at second 0, in 2026, 2028 and 2030",
+			"2026年、2028年和2030年 0秒的每250毫秒",
+		},
+		{defaultParser, "@every 1h30m", "Every 1h30m0s", "每隔 1h30m0s"},
+		{
+			NewRRuleParser(), "DTSTART;TZID=America/New_York:19970902T090000\nRRULE:FREQ=MONTHLY;COUNT=10;BYDAY=1FR,-2MO",
+			"Every month, on the 1st Friday and the 2nd last Monday, at 09:00:00, starting 1997-09-02T09:00:00-04:00, 10 times",
+			"自 1997-09-02T09:00:00-04:00 起，每月，第1个周五和倒数第2个周一，09:00:00，共 10 次",
+		},
+		{
+			NewRRuleParser(), "DTSTART:19970902T090000Z RRULE:FREQ=WEEKLY;INTERVAL=2;UNTIL=19971224T000000Z;BYDAY=TU,TH",
+			"Every 2 weeks, on Tuesday and Thursday, at 09:00:00, starting 1997-09-02T09:00:00Z, until 1997-12-24T00:00:00Z",
+			"自 1997-09-02T09:00:00Z 起，每2周，周二和周四，09:00:00，至 1997-12-24T00:00:00Z 止",
+		},
+		{
+			NewRRuleParser(), "DTSTART:19970101T090000Z RRULE:FREQ=YEARLY;BYMONTH=1,2;BYMONTHDAY=1,-1;BYHOUR=9,17",
+			"Every year, in January and February, on the 1st day and last day of the month, at 00:00 past the hour, at hours 9 and 17, starting 1997-01-01T09:00:00Z",
+			"自 1997-01-01T09:00:00Z 起，每年，一月和二月，每月的第1天和倒数第1天，9点和17点的00分00秒",
+		},
+		{
+			NewRRuleParser(), "DTSTART:19970904T090000Z RRULE:FREQ=MONTHLY;COUNT=3;BYDAY=TU,WE,TH;BYSETPOS=3,-1",
+			"Every month, on Tuesday, Wednesday and Thursday, at 09:00:00, using the 3rd and last occurrence in each month, starting 1997-09-04T09:00:00Z, 3 times",
+			"自 1997-09-04T09:00:00Z 起，每月，周二、周三和周四，09:00:00，取每月中的第3个和倒数第1个，共 3 次",
+		},
+		{
+			NewRRuleParser(), "DTSTART:19970902T090000Z RRULE:FREQ=HOURLY;INTERVAL=3",
+			"Every 3 hours, at 00:00 past the hour, starting 1997-09-02T09:00:00Z",
+			"自 1997-09-02T09:00:00Z 起，每3小时，00分00秒",
+		},
+	}
+
+	for _, test := range tests {
+		sched, err := test.parser.Parse(test.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		if actual := Describe(sched, English); actual != test.english {
+			t.Errorf("Fail describing %s: (expected) %s != %s (actual)", test.spec, test.english, actual)
+		}
+
+		if actual := Describe(sched, Chinese); actual != test.chinese {
+			t.Errorf("Fail describing %s: (expected) %s != %s (actual)", test.spec, test.chinese, actual)
+		}
+	}
+}
@@ -1,9 +1,87 @@
 package beat
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	ErrInvalidExp  = errors.New("invalid expression")
 	ErrJobExist    = errors.New("job already exists")
 	ErrJobNotExist = errors.New("job does not exists")
+	ErrNeverFire   = errors.New("schedule never fires")
+	ErrRareFire    = errors.New("schedule fires rarely")
+
+	ErrInvalidCalendar = errors.New("invalid calendar")
 )
+
+// 表达式解析错误的原因
+type ParseReason uint8
+
+const (
+	ReasonSyntax     ParseReason = iota + 1 // 语法错误
+	ReasonOutOfRange                        // 超出取值范围
+	ReasonStep                              // 步长无效
+	ReasonFieldCount                        // 域的数量不正确
+	ReasonDescriptor                        // 预定义表达式无效
+	ReasonLocation                          // 时区无效
+	ReasonNeverFire                         // 定时永远不会执行
+	ReasonCalendar                          // 日历未注册或调整方式无效
+)
+
+// 表达式解析错误，可使用 errors.Is(err, ErrInvalidExp) 判断，
+// 定时永远不会执行时同时满足 errors.Is(err, ErrNeverFire)
+type ParseError struct {
+	Expr   string      // 原始表达式
+	Field  LayoutField // 出错的域，与域无关时为 0
+	Index  int         // 出错的域在 layout 中的序号，从 0 开始，与域无关时为 -1
+	Offset int         // 出错位置在表达式中的字节偏移
+	Reason ParseReason // 错误原因
+	Msg    string      // 错误详情
+	Err    error       // 具体的错误，如 ErrNeverFire，可为空
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s: %s (offset %d)", ErrInvalidExp, e.Msg, e.Offset)
+}
+
+func (e *ParseError) Unwrap() []error {
+	if e.Err != nil {
+		return []error{ErrInvalidExp, e.Err}
+	}
+
+	return []error{ErrInvalidExp}
+}
+
+// 创建解析错误，offset 为相对于当前解析内容的偏移，由调用者通过 shiftError 修正
+func newParseError(reason ParseReason, offset int, format string, a ...any) *ParseError {
+	return &ParseError{
+		Index:  -1,
+		Offset: offset,
+		Reason: reason,
+		Msg:    fmt.Sprintf(format, a...),
+	}
+}
+
+// 将解析错误的偏移增加 offset
+func shiftError(err error, offset int) error {
+	var pe *ParseError
+	if errors.As(err, &pe) {
+		pe.Offset += offset
+	}
+
+	return err
+}
+
+// 为解析错误补充表达式及所在的域，offset 为域在表达式中的偏移
+func locateError(err error, exp string, lf LayoutField, index, offset int) error {
+	var pe *ParseError
+	if errors.As(err, &pe) {
+		pe.Expr = exp
+		pe.Field = lf
+		pe.Index = index
+		pe.Offset += offset
+	}
+
+	return err
+}
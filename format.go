@@ -0,0 +1,262 @@
+package beat
+
+import (
+	"fmt"
+	"math/bits"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 生成规范的表达式，使用解析时的 layout 及星期表示方式，
+// 使用相同的解析器解析该表达式将得到相同的定时。
+//
+// 时区为 time.Local 时省略 TZ= 前缀，否则时区名称必须能被 time.LoadLocation 加载
+func (st *SchedTime) String() string {
+	layout := st.layout
+	if layout == nil {
+		layout = DefaultLayout
+	}
+
+	fields := make([]string, 0, len(layout)+1)
+
+	if st.location != nil && st.location != time.Local {
+		fields = append(fields, "TZ="+st.location.String())
+	}
+
+	for _, lf := range layout {
+		fields = append(fields, st.formatField(lf))
+	}
+
+	return strings.Join(fields, " ")
+}
+
+// 生成单个域的表达式
+func (st *SchedTime) formatField(lf LayoutField) string {
+	switch lf {
+	case Month:
+		return formatBits(st.Month, Month.valueRange())
+
+	case Dom:
+		return st.keepStar(st.formatDom(), domStar, Dom.valueRange())
+
+	case Dow:
+		return st.keepStar(st.formatDow(), dowStar, st.dowNumbering.valueRange())
+
+	case Hour:
+		return formatBits(st.Hour, Hour.valueRange())
+
+	case Minute:
+		return formatBits(st.Minute, Minute.valueRange())
+
+	case Second:
+		return formatBits(st.Second, Second.valueRange())
+
+	case Year:
+		if len(st.Year) == 0 {
+			return "*"
+		}
+		return formatValues(st.Year, Year.valueRange())
+
+	case Millisecond:
+		if len(st.Millisecond) == 0 {
+			return "0"
+		}
+		return formatValues(st.Millisecond, Millisecond.valueRange())
+	}
+
+	return ""
+}
+
+// 解析时不以 * 开头的“日”或“星期”仍生成不以 * 开头的表达式，如 1-31，
+// 以免改变 DayMatchOr 的组合方式
+func (st *SchedTime) keepStar(field string, star dayStars, vr valueRange) string {
+	if st.dayStars&starParsed == 0 || st.dayStars&star != 0 || !strings.HasPrefix(field, "*") {
+		return field
+	}
+
+	return strconv.Itoa(vr.min) + "-" + strconv.Itoa(vr.max) + field[1:]
+}
+
+// 生成“日”域的表达式
+func (st *SchedTime) formatDom() string {
+	if st.Dom == Dom.all() {
+		// Quartz 中“日”和“星期”必须有且仅有一个为 ?，都不限制时“星期”为 ?
+		if st.dowNumbering == dowQuartz && st.Dow != Dow.all() {
+			return "?"
+		}
+		return "*"
+	}
+
+	parts := make([]string, 0)
+
+	if st.Dom != 0 {
+		parts = append(parts, formatBits(st.Dom, Dom.valueRange()))
+	}
+
+	for n := range 31 {
+		if (1<<n)&st.LastDays == 0 {
+			continue
+		}
+
+		if n == 0 {
+			parts = append(parts, "L")
+		} else {
+			parts = append(parts, fmt.Sprintf("L-%d", n))
+		}
+	}
+
+	if st.LastWeekday {
+		parts = append(parts, "LW")
+	}
+
+	for n := 1; n <= 31; n++ {
+		if (1<<n)&st.NearestWeekday != 0 {
+			parts = append(parts, fmt.Sprintf("%dW", n))
+		}
+	}
+
+	return strings.Join(parts, ",")
+}
+
+// 生成“星期”域的表达式，星期按解析时的表示方式输出
+func (st *SchedTime) formatDow() string {
+	numbering := st.dowNumbering
+
+	if st.Dow == Dow.all() {
+		if numbering == dowQuartz {
+			return "?"
+		}
+		return "*"
+	}
+
+	parts := make([]string, 0)
+
+	if st.Dow != 0 {
+		values := make([]int, 0, 7)
+		for dow := 1; dow <= 7; dow++ {
+			if (1<<dow)&st.Dow != 0 {
+				values = append(values, numbering.fromISO(dow))
+			}
+		}
+		slices.Sort(values)
+
+		parts = append(parts, formatValues(values, numbering.valueRange()))
+	}
+
+	for dow := 1; dow <= 7; dow++ {
+		if (1<<dow)&st.LastDow != 0 {
+			parts = append(parts, fmt.Sprintf("%dL", numbering.fromISO(dow)))
+		}
+	}
+
+	for k := 1; k <= 5; k++ {
+		for dow := 1; dow <= 7; dow++ {
+			if (1<<(k*8+dow))&st.NthDow != 0 {
+				parts = append(parts, fmt.Sprintf("%d#%d", numbering.fromISO(dow), k))
+			}
+		}
+	}
+
+	return strings.Join(parts, ",")
+}
+
+// 将 ISO 8601 表示的星期转换为当前表示方式
+func (n dowNumbering) fromISO(dow int) int {
+	switch n {
+	case dowCrontab:
+		if dow == 7 {
+			return 0
+		}
+
+	case dowQuartz:
+		if dow == 7 {
+			return 1
+		}
+		return dow + 1
+	}
+
+	return dow
+}
+
+// 将位图形式的域生成表达式
+func formatBits(b uint64, vr valueRange) string {
+	return formatValues(bitValues(b), vr)
+}
+
+// 获取位图中的全部有效值
+func bitValues(b uint64) []int {
+	values := make([]int, 0, bits.OnesCount64(b))
+	for b != 0 {
+		i := bits.TrailingZeros64(b)
+		values = append(values, i)
+		b &^= 1 << i
+	}
+
+	return values
+}
+
+// 将升序排列的有效值生成紧凑的表达式
+//
+// 全部有效时生成 *，等差数列生成 a-b/n 或 a/n，其余合并连续的值为 a-b
+func formatValues(values []int, vr valueRange) string {
+	if len(values) == vr.max-vr.min+1 {
+		return "*"
+	}
+
+	if step, ok := commonStep(values); ok && step > 1 {
+		first, last := values[0], values[len(values)-1]
+
+		switch {
+		case last+step <= vr.max:
+			return fmt.Sprintf("%d-%d/%d", first, last, step)
+		case first == vr.min:
+			return fmt.Sprintf("*/%d", step)
+		default:
+			return fmt.Sprintf("%d/%d", first, step)
+		}
+	}
+
+	parts := make([]string, 0)
+	for i := 0; i < len(values); {
+		j := i
+		for j+1 < len(values) && values[j+1] == values[j]+1 {
+			j++
+		}
+
+		switch {
+		case j-i >= 2:
+			parts = append(parts, fmt.Sprintf("%d-%d", values[i], values[j]))
+		default:
+			for k := i; k <= j; k++ {
+				parts = append(parts, strconv.Itoa(values[k]))
+			}
+		}
+
+		i = j + 1
+	}
+
+	return strings.Join(parts, ",")
+}
+
+// 获取至少 3 个值的等差数列的公差
+func commonStep(values []int) (int, bool) {
+	if len(values) < 3 {
+		return 0, false
+	}
+
+	step := values[1] - values[0]
+	for i := 2; i < len(values); i++ {
+		if values[i]-values[i-1] != step {
+			return 0, false
+		}
+	}
+
+	return step, true
+}
+
+// 生成 @every 表达式
+func (se *SchedEvery) String() string {
+	return "@every " + se.Interval.String()
+}
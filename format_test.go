@@ -0,0 +1,122 @@
+package beat
+
+import (
+	"math/rand/v2"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSchedTimeString(t *testing.T) {
+	yearLayout := append(append([]LayoutField{}, DefaultLayout...), Year, Millisecond)
+
+	tests := []struct {
+		parser   ScheduleParser
+		spec     string
+		expected string
+	}{
+		{defaultParser, "* * * * * *", "* * * * * *"},
+		{defaultParser, "1-12 1-31 1-7 0-23 0-59 0-59", "* * * * * *"},
+		{defaultParser, "* * * * */15 0", "* * * * */15 0"},
+		{defaultParser, "* * * * 5/15 0", "* * * * 5/15 0"},
+		{defaultParser, "* * * 8-18/2 0,15,30,45 0", "* * * 8-18/2 */15 0"},
+		{defaultParser, "JAN-MAR,jun 1,2,5,6,7,8 MON-FRI 9 30 0", "1-3,6 1,2,5-8 1-5 9 30 0"},
+		{defaultParser, "* L,L-2,LW,15W ? 0 0 0", "* L,L-2,LW,15W * 0 0 0"},
+		{defaultParser, "* * 5L,TUE#2,1#1 0 0 0", "* * 5L,1#1,2#2 0 0 0"},
+		{defaultParser, "TZ=Asia/Shanghai @daily", "TZ=Asia/Shanghai * * * 0 0 0"},
+		{defaultParser, "@weekly", "* * 7 0 0 0"},
+		{NewParser(WithLayout(yearLayout)), "* * * * * 0 2026-2030/2 */250", "* * * * * 0 2026-2030/2 */250"},
+		{NewParser(WithLayout(yearLayout)), "* * * * * 0 * 0", "* * * * * 0 * 0"},
+		{NewCrontabParser(), "30 9 * * 0-2", "30 9 * * 0-2"},
+		{NewCrontabParser(), "30 9 * * 7", "30 9 * * 0"},
+		{NewCrontabParser(), "0 30 9 1,15 * sun", "0 30 9 1,15 * 0"},
+		{NewCrontabParser(), "0 0 1-31 * 1", "0 0 1-31 * 1"},
+		{NewCrontabParser(), "0 0 */2 * mon", "0 0 */2 * 1"},
+		{NewQuartzParser(), "0 15 10 ? * 6L", "0 15 10 ? * 6L"},
+		{NewQuartzParser(), "0 15 10 ? * 2-6 2030", "0 15 10 ? * 2-6 2030"},
+		{NewQuartzParser(), "0 15 10 L * ?", "0 15 10 L * ?"},
+		{NewQuartzParser(), "0 0 12 * * ?", "0 0 12 * * ?"},
+	}
+
+	for _, test := range tests {
+		sched, err := test.parser.Parse(test.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		actual := sched.(*SchedTime).String()
+		if actual != test.expected {
+			t.Errorf("Fail formatting %s: (expected) %s != %s (actual)", test.spec, test.expected, actual)
+			continue
+		}
+
+		parsed, err := test.parser.Parse(actual)
+		if err != nil {
+			t.Errorf("Fail parsing %s: %v", actual, err)
+			continue
+		}
+
+		if !equalSchedTime(sched.(*SchedTime), parsed.(*SchedTime)) {
+			t.Errorf("Fail round trip of %s: %+v != %+v", test.spec, sched, parsed)
+		}
+	}
+
+	if actual := (&SchedEvery{Interval: 90 * 60 * 1e9}).String(); actual != "@every 1h30m0s" {
+		t.Errorf("unexpected @every expression %s", actual)
+	}
+}
+
+// 比较两个 SchedTime 是否相同，时区仅比较名称
+func equalSchedTime(a, b *SchedTime) bool {
+	x, y := *a, *b
+	if x.location.String() != y.location.String() {
+		return false
+	}
+
+	x.location, y.location = nil, nil
+	return reflect.DeepEqual(x, y)
+}
+
+// Random sets of values survive a round trip through String and Parse.
+func TestSchedTimeStringRandom(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(1, 2))
+
+	randomField := func(lf LayoutField) string {
+		min, max := lf.Bounds()
+		values := make([]string, 0)
+		for i := min; i <= max; i++ {
+			if rnd.IntN(3) == 0 {
+				values = append(values, strconv.Itoa(i))
+			}
+		}
+		if len(values) == 0 {
+			return strconv.Itoa(min)
+		}
+		return strings.Join(values, ",")
+	}
+
+	for i := 0; i < 500; i++ {
+		fields := make([]string, 0, len(DefaultLayout))
+		for _, lf := range DefaultLayout {
+			fields = append(fields, randomField(lf))
+		}
+		spec := strings.Join(fields, " ")
+
+		sched, err := defaultParser.Parse(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		actual := sched.(*SchedTime).String()
+		parsed, err := defaultParser.Parse(actual)
+		if err != nil {
+			t.Fatalf("Fail parsing %s formatted from %s: %v", actual, spec, err)
+		}
+
+		if !equalSchedTime(sched.(*SchedTime), parsed.(*SchedTime)) {
+			t.Fatalf("Fail round trip of %s: %s", spec, actual)
+		}
+	}
+}
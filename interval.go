@@ -0,0 +1,75 @@
+package beat
+
+import "time"
+
+// 以锚点时间为起点、按固定周期执行的定时，有效时间为 Anchor + k*Period (k >= 0)，
+// 不受月、日、时等域的边界影响，如从某时刻起每 36 小时执行一次
+type IntervalSchedule struct {
+	Anchor time.Time     // 起点，即第一次执行的时间
+	Period time.Duration // 周期，必须大于 0
+	End    time.Time     // 结束时间 (含)，零值表示不结束
+}
+
+// 创建以 anchor 为起点、每隔 period 执行的定时，可通过 End 指定结束时间
+func NewIntervalSchedule(anchor time.Time, period time.Duration) *IntervalSchedule {
+	return &IntervalSchedule{Anchor: anchor, Period: period}
+}
+
+// 获取下一个有效时间，早于起点时返回起点，超过结束时间返回零值时间
+func (is *IntervalSchedule) Next(t time.Time) time.Time {
+	if is.Period <= 0 {
+		return time.Time{}
+	}
+
+	next := is.Anchor
+	if !t.Before(is.Anchor) {
+		next = is.Anchor.Add((t.Sub(is.Anchor)/is.Period + 1) * is.Period)
+	}
+
+	if !is.End.IsZero() && next.After(is.End) {
+		return time.Time{}
+	}
+
+	return next.In(t.Location())
+}
+
+// 获取前一个有效时间，不晚于起点时返回零值时间
+func (is *IntervalSchedule) Prev(t time.Time) time.Time {
+	if is.Period <= 0 || !t.After(is.Anchor) {
+		return time.Time{}
+	}
+
+	if !is.End.IsZero() && t.After(is.End) {
+		// 结束时间之后的前一个有效时间即最后一个有效时间
+		t = is.End.Add(time.Nanosecond)
+	}
+
+	k := (t.Sub(is.Anchor) - 1) / is.Period
+	return is.Anchor.Add(k * is.Period).In(t.Location())
+}
+
+// 生成 @every <duration> from <time> [until <time>] 表达式
+func (is *IntervalSchedule) String() string {
+	s := "@every " + is.Period.String() + " from " + is.Anchor.Format(time.RFC3339Nano)
+	if !is.End.IsZero() {
+		s += " until " + is.End.Format(time.RFC3339Nano)
+	}
+
+	return s
+}
+
+func (is *IntervalSchedule) describe(lang Language) string {
+	if lang == Chinese {
+		s := "从 " + is.Anchor.Format(time.RFC3339Nano) + " 起每隔 " + is.Period.String()
+		if !is.End.IsZero() {
+			s += "，至 " + is.End.Format(time.RFC3339Nano) + " 结束"
+		}
+		return s
+	}
+
+	s := "Every " + is.Period.String() + " from " + is.Anchor.Format(time.RFC3339Nano)
+	if !is.End.IsZero() {
+		s += " until " + is.End.Format(time.RFC3339Nano)
+	}
+	return s
+}
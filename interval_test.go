@@ -0,0 +1,111 @@
+package beat
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestIntervalSchedule(t *testing.T) {
+	sched := NewIntervalSchedule(parseTime("2026-01-01T00:00:00Z"), 36*time.Hour)
+	sched.End = parseTime("2026-01-07T00:00:00Z")
+
+	tests := []struct {
+		time string
+		next string
+		prev string
+	}{
+		{"2025-12-31T00:00:00Z", "2026-01-01T00:00:00Z", ""},
+		{"2026-01-01T00:00:00Z", "2026-01-02T12:00:00Z", ""},
+		{"2026-01-02T11:59:59Z", "2026-01-02T12:00:00Z", "2026-01-01T00:00:00Z"},
+		{"2026-01-02T12:00:00Z", "2026-01-04T00:00:00Z", "2026-01-01T00:00:00Z"},
+		{"2026-01-05T12:00:00Z", "2026-01-07T00:00:00Z", "2026-01-04T00:00:00Z"},
+		{"2026-01-07T00:00:00Z", "", "2026-01-05T12:00:00Z"},
+		{"2026-02-01T00:00:00Z", "", "2026-01-07T00:00:00Z"},
+	}
+
+	for _, test := range tests {
+		now := parseTime(test.time)
+
+		if actual := sched.Next(now); !actual.Equal(parseTime(test.next)) {
+			t.Errorf("Fail evaluating on %s: (expected) %s != %s (actual)", test.time, test.next, actual)
+		}
+		if actual := sched.Prev(now); !actual.Equal(parseTime(test.prev)) {
+			t.Errorf("Fail reversing on %s: (expected) %s != %s (actual)", test.time, test.prev, actual)
+		}
+	}
+
+	// 不受小时边界影响
+	every7 := NewIntervalSchedule(parseTime("2026-01-01T00:00:00Z"), 7*time.Minute)
+	if actual := every7.Next(parseTime("2026-01-01T00:59:00Z")); !actual.Equal(parseTime("2026-01-01T01:03:00Z")) {
+		t.Errorf("unexpected next time %s", actual)
+	}
+}
+
+func TestParseInterval(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected *IntervalSchedule
+	}{
+		{
+			"@every 36h from 2026-01-01T00:00:00Z",
+			&IntervalSchedule{Anchor: parseTime("2026-01-01T00:00:00Z"), Period: 36 * time.Hour},
+		},
+		{
+			"@every 7m FROM 2026-01-01T08:00:00+08:00 until 2026-02-01T00:00:00Z",
+			&IntervalSchedule{
+				Anchor: parseTime("2026-01-01T08:00:00+08:00"),
+				Period: 7 * time.Minute,
+				End:    parseTime("2026-02-01T00:00:00Z"),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		sched, err := defaultParser.Parse(test.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		actual, ok := sched.(*IntervalSchedule)
+		if !ok || !actual.Anchor.Equal(test.expected.Anchor) || actual.Period != test.expected.Period ||
+			!actual.End.Equal(test.expected.End) {
+			t.Errorf("Fail parsing %s: (expected) %+v != %+v (actual)", test.spec, test.expected, sched)
+			continue
+		}
+
+		parsed, err := defaultParser.Parse(actual.String())
+		if err != nil || !parsed.(*IntervalSchedule).Anchor.Equal(actual.Anchor) || !parsed.(*IntervalSchedule).End.Equal(actual.End) {
+			t.Errorf("Fail round trip of %s: %s, %v", test.spec, actual.String(), err)
+		}
+	}
+
+	sched, err := defaultParser.Parse("@every 36h from 2026-01-01T00:00:00Z until 2026-02-01T00:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual := Describe(sched, English); actual != "Every 36h0m0s from 2026-01-01T00:00:00Z until 2026-02-01T00:00:00Z" {
+		t.Errorf("unexpected description %s", actual)
+	}
+	if actual := Describe(sched, Chinese); actual != "从 2026-01-01T00:00:00Z 起每隔 36h0m0s，至 2026-02-01T00:00:00Z 结束" {
+		t.Errorf("unexpected description %s", actual)
+	}
+
+	for spec, offset := range map[string]int{
+		"@every 1h from":                                                 10,
+		"@every 1h since 2026-01-01T00:00:00Z":                           10,
+		"@every 1h from 2026-01-01":                                      15,
+		"@every 1h from 2026-01-01T00:00:00Z until":                      36,
+		"@every 1h from 2026-01-01T00:00:00Z to now":                     36,
+		"@every 1h from 2026-01-01T00:00:00Z until 20":                   42,
+		"@every 1h from 2026-01-02T00:00:00Z until 2026-01-01T00:00:00Z": 42,
+	} {
+		_, err := defaultParser.Parse(spec)
+
+		var pe *ParseError
+		if !errors.As(err, &pe) || pe.Reason != ReasonDescriptor || pe.Offset != offset {
+			t.Errorf("unexpected error for %s: %v", spec, err)
+		}
+	}
+}
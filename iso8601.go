@@ -0,0 +1,437 @@
+package beat
+
+import (
+	"math"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 可解析的 ISO 8601 时间格式，未带时区的使用缺省时区
+var isoTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04",
+	"20060102T150405Z0700",
+	"20060102T150405",
+	"2006-01-02",
+}
+
+// ISO 8601 时长，年、月、日按日历计算，时、分、秒为固定时长
+//
+// 按月增加时若目标月份没有对应的日，则取该月最后一天，如 1 月 31 日加 1 个月为 2 月 28 日或 29 日
+type ISODuration struct {
+	Years  int           // 年
+	Months int           // 月
+	Days   int           // 日，周按 7 天计算
+	Time   time.Duration // 时、分、秒
+}
+
+// ISO 8601 重复间隔定时，第 k 次执行的时间为 Start 加上 k 个 Period，k 从 0 开始
+type SchedRepeating struct {
+	Start  time.Time   // 第一次执行的时间
+	Period ISODuration // 间隔
+	Count  int         // 执行次数，0 表示不限制
+}
+
+// ISO 8601 重复间隔解析器
+//
+// 支持以下形式的表达式：
+//
+//	Rn/<start>/<duration>  从 start 起每隔 duration 执行，如 R5/2026-11-01T00:00:00Z/PT6H
+//	Rn/<start>/<end>       间隔为 end 与 start 之差
+//	Rn/<duration>/<end>    从 end 之前一个间隔起每隔 duration 执行
+//	Rn/<duration>          从解析时起每隔 duration 执行，如 R/P1W
+//
+// n 为执行次数，省略时不限制；Rn/<duration> 的第一次执行为解析时之后的一个间隔，
+// 每次解析都会重新开始计数，需要跨越重启保持次数和相位时应指定开始或结束时间；时长支持 PnYnMnWnDTnHnMnS，时、分、秒可以带小数；
+// 时间使用 ISO 8601 格式，如 2026-11-01T08:00:00+08:00，未带时区时使用缺省时区
+type ISO8601Parser struct {
+	location *time.Location // 缺省时区
+}
+
+// 创建 ISO 8601 重复间隔解析器，opts 中仅 WithDefaultLocation 有效
+func NewISO8601Parser(opts ...parserOption) *ISO8601Parser {
+	return &ISO8601Parser{location: NewParser(opts...).defaultLoction}
+}
+
+// 解析 ISO 8601 重复间隔表达式
+func (p *ISO8601Parser) Parse(exp string) (Schedule, error) {
+	trimmed := strings.TrimSpace(exp)
+	base := strings.Index(exp, trimmed)
+	parts := strings.Split(trimmed, "/")
+
+	if !strings.HasPrefix(strings.ToUpper(parts[0]), "R") {
+		return nil, locateError(newParseError(ReasonSyntax, base, "missing repetition 'Rn'"), exp, 0, -1, 0)
+	}
+	if len(parts) < 2 || len(parts) > 3 {
+		return nil, locateError(newParseError(ReasonFieldCount, base+len(trimmed), "expected Rn/<interval>"), exp, 0, -1, 0)
+	}
+
+	sched := &SchedRepeating{}
+
+	if n := parts[0][1:]; n != "" {
+		count, err := strconv.Atoi(n)
+		if err != nil || count < 0 {
+			return nil, locateError(newParseError(ReasonSyntax, base+1, "bad repetition '%s'", n), exp, 0, -1, 0)
+		}
+		if count == 0 {
+			pe := newParseError(ReasonNeverFire, base, "%s", ErrNeverFire)
+			pe.Err = ErrNeverFire
+			return nil, locateError(pe, exp, 0, -1, 0)
+		}
+		sched.Count = count
+	}
+
+	// 各部分在表达式中的偏移
+	offsets := make([]int, len(parts))
+	for i := 1; i < len(parts); i++ {
+		offsets[i] = offsets[i-1] + len(parts[i-1]) + 1
+	}
+
+	var err error
+	switch {
+	case len(parts) == 2:
+		sched.Period, err = parseISODuration(parts[1])
+		if err != nil {
+			err = shiftError(err, offsets[1])
+			break
+		}
+
+		// 第一次执行为解析时之后的一个间隔，保证 n 次都能执行；尽量对齐到整秒
+		now := time.Now().In(p.location)
+		sched.Start = sched.Period.addTo(now.Truncate(time.Second), 1)
+		if !sched.Start.After(now) {
+			sched.Start = sched.Period.addTo(now, 1)
+		}
+
+	case isISODuration(parts[1]):
+		sched.Period, err = parseISODuration(parts[1])
+		if err != nil {
+			err = shiftError(err, offsets[1])
+			break
+		}
+
+		var end time.Time
+		end, err = p.parseTime(parts[2])
+		sched.Start = sched.Period.subtractFrom(end)
+		err = shiftError(err, offsets[2])
+
+	default:
+		sched.Start, err = p.parseTime(parts[1])
+		if err != nil {
+			err = shiftError(err, offsets[1])
+			break
+		}
+
+		if isISODuration(parts[2]) {
+			sched.Period, err = parseISODuration(parts[2])
+		} else {
+			var end time.Time
+			end, err = p.parseTime(parts[2])
+			sched.Period = ISODuration{Time: end.Sub(sched.Start)}
+		}
+		err = shiftError(err, offsets[2])
+	}
+	if err != nil {
+		return nil, locateError(err, exp, 0, -1, base)
+	}
+
+	if !sched.Period.positive() {
+		pe := newParseError(ReasonOutOfRange, offsets[len(offsets)-1], "interval must be positive")
+		return nil, locateError(pe, exp, 0, -1, base)
+	}
+
+	// 查找范围内的执行次数需在 int 的范围内
+	if _, ok := sched.estimate(sched.Start.AddDate(gregorianCycle, 0, 0)); !ok {
+		pe := newParseError(ReasonOutOfRange, offsets[len(offsets)-1], "interval too small")
+		return nil, locateError(pe, exp, 0, -1, base)
+	}
+
+	return sched, nil
+}
+
+// 解析 ISO 8601 时间
+func (p *ISO8601Parser) parseTime(value string) (time.Time, error) {
+	for _, layout := range isoTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, p.location); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, newParseError(ReasonSyntax, 0, "bad time '%s'", value)
+}
+
+// 判断是否为时长，即以 P 开头
+func isISODuration(value string) bool {
+	return strings.HasPrefix(strings.ToUpper(value), "P")
+}
+
+// 解析 ISO 8601 时长，如 P1Y2M3DT4H5M6.5S、P1W
+func parseISODuration(value string) (ISODuration, error) {
+	d := ISODuration{}
+
+	if !isISODuration(value) || len(value) == 1 {
+		return d, newParseError(ReasonSyntax, 0, "bad duration '%s'", value)
+	}
+
+	// 已解析的单位，用于检查单位的顺序，日期和时间部分分别使用 YMWD 和 HMS 的顺序
+	order, last := "YMWD", -1
+	inTime := false
+
+	pos := 1
+	for pos < len(value) {
+		if c := value[pos]; c == 'T' || c == 't' {
+			if inTime || pos == len(value)-1 {
+				return d, newParseError(ReasonSyntax, pos, "unexpected 'T'")
+			}
+			order, last, inTime = "HMS", -1, true
+			pos++
+			continue
+		}
+
+		end := pos
+		for end < len(value) && (value[end] >= '0' && value[end] <= '9' || value[end] == '.' || value[end] == ',') {
+			end++
+		}
+		if end == pos || end == len(value) {
+			return d, newParseError(ReasonSyntax, pos, "bad duration '%s'", value)
+		}
+
+		unit := strings.IndexByte(order, value[end]&^0x20)
+		if unit <= last {
+			return d, newParseError(ReasonSyntax, end, "unexpected unit '%c'", value[end])
+		}
+		last = unit
+
+		number := strings.Replace(value[pos:end], ",", ".", 1)
+		if !inTime {
+			n, err := strconv.Atoi(number)
+			if err != nil {
+				return d, newParseError(ReasonSyntax, pos, "bad number '%s'", number)
+			}
+
+			switch order[unit] {
+			case 'Y':
+				d.Years += n
+			case 'M':
+				d.Months += n
+			case 'W':
+				d.Days += 7 * n
+			case 'D':
+				d.Days += n
+			}
+		} else {
+			f, err := strconv.ParseFloat(number, 64)
+			if err != nil {
+				return d, newParseError(ReasonSyntax, pos, "bad number '%s'", number)
+			}
+
+			unitDuration := map[byte]time.Duration{'H': time.Hour, 'M': time.Minute, 'S': time.Second}[order[unit]]
+			if f*float64(unitDuration) > math.MaxInt64/2 {
+				return d, newParseError(ReasonOutOfRange, pos, "duration too large: %s", number)
+			}
+			d.Time += time.Duration(f * float64(unitDuration))
+		}
+
+		pos = end + 1
+	}
+
+	return d, nil
+}
+
+// 判断时长是否大于 0
+func (d ISODuration) positive() bool {
+	return d.approx() > 0 && d.Years >= 0 && d.Months >= 0 && d.Days >= 0 && d.Time >= 0
+}
+
+// 获取时长的近似秒数，年、月按公历的平均长度计算
+func (d ISODuration) approx() float64 {
+	const day = 24 * 3600
+	return float64(d.Years)*365.2425*day + float64(d.Months)*30.436875*day + float64(d.Days)*day + d.Time.Seconds()
+}
+
+// 获取 t 加上 k 个时长后的时间
+func (d ISODuration) addTo(t time.Time, k int) time.Time {
+	year, month, day := t.Date()
+	hour, minute, sec := t.Clock()
+
+	// 目标月份没有对应的日时取该月最后一天
+	months := int(month) - 1 + k*(d.Years*12+d.Months)
+	year, month = year+floorDiv(months, 12), time.Month(months-floorDiv(months, 12)*12+1)
+	day = min(day, daysIn(year, month))
+
+	next := time.Date(year, month, day+k*d.Days, hour, minute, sec, t.Nanosecond(), t.Location())
+
+	// 分别累加秒和纳秒，避免 k 个时长超出 time.Duration 的范围
+	q, r := int64(d.Time/time.Second), int64(d.Time%time.Second)
+	kh, kl := int64(k)/int64(time.Second), int64(k)%int64(time.Second)
+	secs := int64(k)*q + kh*r + kl*r/int64(time.Second)
+	nanos := kl * r % int64(time.Second)
+	return time.Unix(next.Unix()+secs, int64(next.Nanosecond())+nanos).In(next.Location())
+}
+
+// 获取 t 减去一个时长后的时间
+func (d ISODuration) subtractFrom(t time.Time) time.Time {
+	return ISODuration{Years: -d.Years, Months: -d.Months, Days: -d.Days, Time: -d.Time}.addTo(t, 1)
+}
+
+// 生成 ISO 8601 时长表达式
+func (d ISODuration) String() string {
+	var b strings.Builder
+	b.WriteString("P")
+
+	for _, part := range []struct {
+		n    int
+		unit string
+	}{{d.Years, "Y"}, {d.Months, "M"}, {d.Days, "D"}} {
+		if part.n != 0 {
+			b.WriteString(strconv.Itoa(part.n) + part.unit)
+		}
+	}
+
+	if d.Time != 0 {
+		b.WriteString("T")
+		hours, rest := d.Time/time.Hour, d.Time%time.Hour
+		minutes, rest := rest/time.Minute, rest%time.Minute
+		if hours != 0 {
+			b.WriteString(strconv.Itoa(int(hours)) + "H")
+		}
+		if minutes != 0 {
+			b.WriteString(strconv.Itoa(int(minutes)) + "M")
+		}
+		if rest != 0 {
+			b.WriteString(strconv.FormatFloat(rest.Seconds(), 'f', -1, 64) + "S")
+		}
+	}
+
+	if b.Len() == 1 {
+		b.WriteString("T0S")
+	}
+
+	return b.String()
+}
+
+// 获取第 k 次执行的时间
+func (sr *SchedRepeating) at(k int) time.Time {
+	return sr.Period.addTo(sr.Start, k)
+}
+
+// 估算 t 之前的执行次数，用于快速定位，次数超出 int 的范围时返回 false
+//
+// 仅有时、分、秒的时长按纳秒精确计算，含年、月、日时按平均长度估算
+func (sr *SchedRepeating) estimate(t time.Time) (int, bool) {
+	d := sr.Period
+	if d.Years != 0 || d.Months != 0 || d.Days != 0 {
+		k := float64(t.Unix()-sr.Start.Unix()) / d.approx()
+		if k < 0 {
+			return 0, true
+		}
+		if k >= math.MaxInt {
+			return 0, false
+		}
+		return int(k), true
+	}
+
+	secs, nanos := t.Unix()-sr.Start.Unix(), int64(t.Nanosecond()-sr.Start.Nanosecond())
+	if nanos < 0 {
+		secs, nanos = secs-1, nanos+int64(time.Second)
+	}
+	if secs < 0 {
+		return 0, true
+	}
+
+	// 相差的纳秒数可能超出 int64 的范围，按 128 位整数计算
+	hi, lo := bits.Mul64(uint64(secs), uint64(time.Second))
+	lo, carry := bits.Add64(lo, uint64(nanos), 0)
+	hi += carry
+	if hi >= uint64(d.Time) {
+		return 0, false
+	}
+
+	// 保留 k+1 的余量
+	k, _ := bits.Div64(hi, lo, uint64(d.Time))
+	if k >= math.MaxInt {
+		return 0, false
+	}
+	return int(k), true
+}
+
+// 获取下一个有效时间，超过执行次数时返回零值时间
+func (sr *SchedRepeating) Next(t time.Time) time.Time {
+	if !sr.Period.positive() {
+		return time.Time{}
+	}
+
+	// 找到第一个晚于 t 的时间
+	k, ok := sr.estimate(t)
+	if !ok {
+		return time.Time{}
+	}
+	for k > 0 && sr.at(k-1).After(t) {
+		k--
+	}
+	for !sr.at(k).After(t) {
+		k++
+	}
+
+	if sr.Count > 0 && k >= sr.Count {
+		return time.Time{}
+	}
+
+	return sr.at(k).In(t.Location())
+}
+
+// 获取前一个有效时间，早于第一次执行时返回零值时间
+func (sr *SchedRepeating) Prev(t time.Time) time.Time {
+	if !sr.Period.positive() || !sr.Start.Before(t) {
+		return time.Time{}
+	}
+
+	// 找到最后一个早于 t 的时间
+	k, ok := sr.estimate(t)
+	if !ok {
+		return time.Time{}
+	}
+	if sr.Count > 0 {
+		k = min(k, sr.Count-1)
+	}
+	for k > 0 && !sr.at(k).Before(t) {
+		k--
+	}
+	for (sr.Count == 0 || k+1 < sr.Count) && sr.at(k+1).Before(t) {
+		k++
+	}
+
+	return sr.at(k).In(t.Location())
+}
+
+// 生成 ISO 8601 重复间隔表达式
+func (sr *SchedRepeating) String() string {
+	count := ""
+	if sr.Count > 0 {
+		count = strconv.Itoa(sr.Count)
+	}
+
+	return "R" + count + "/" + sr.Start.Format(time.RFC3339Nano) + "/" + sr.Period.String()
+}
+
+func (sr *SchedRepeating) describe(lang Language) string {
+	start := sr.Start.Format(time.RFC3339Nano)
+
+	if lang == Chinese {
+		s := "从 " + start + " 起每隔 " + sr.Period.String()
+		if sr.Count > 0 {
+			s += "，共 " + strconv.Itoa(sr.Count) + " 次"
+		}
+		return s
+	}
+
+	s := "Every " + sr.Period.String() + " from " + start
+	if sr.Count > 0 {
+		s += ", " + strconv.Itoa(sr.Count) + " times"
+	}
+	return s
+}
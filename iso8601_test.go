@@ -0,0 +1,178 @@
+package beat
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestISO8601Parser(t *testing.T) {
+	tests := []struct {
+		spec     string
+		time     string
+		expected string
+	}{
+		{"R5/2026-11-01T00:00:00Z/PT6H", "2026-10-01T00:00:00Z", "2026-11-01T00:00:00Z"},
+		{"R5/2026-11-01T00:00:00Z/PT6H", "2026-11-01T00:00:00Z", "2026-11-01T06:00:00Z"},
+		{"R5/2026-11-01T00:00:00Z/PT6H", "2026-11-01T23:00:00Z", "2026-11-02T00:00:00Z"},
+		{"R5/2026-11-01T00:00:00Z/PT6H", "2026-11-02T00:00:00Z", ""},
+		{"R/2026-11-01T00:00:00Z/PT6H", "2036-11-01T01:00:00Z", "2036-11-01T06:00:00Z"},
+		{"R/2024-01-31T12:00:00Z/P1M", "2024-02-01T00:00:00Z", "2024-02-29T12:00:00Z"},
+		{"R/2024-01-31T12:00:00Z/P1M", "2024-02-29T12:00:00Z", "2024-03-31T12:00:00Z"},
+		{"R/2024-02-29T00:00:00Z/P1Y", "2024-03-01T00:00:00Z", "2025-02-28T00:00:00Z"},
+		{"R/2024-02-29T00:00:00Z/P1Y", "2027-03-01T00:00:00Z", "2028-02-29T00:00:00Z"},
+		{"R/2024-01-01T00:00:00Z/P1Y2M10DT2H30M", "2024-01-01T00:00:00Z", "2025-03-11T02:30:00Z"},
+		{"R/2024-01-01T00:00:00Z/P2W", "2024-01-01T00:00:00Z", "2024-01-15T00:00:00Z"},
+		{"R/2024-01-01T00:00:00Z/PT0.5S", "2024-01-01T00:00:00Z", "2024-01-01T00:00:00.5Z"},
+		// 距开始时间很久的亚秒级间隔按纳秒精确定位
+		{"R/2000-01-01T00:00:00Z/PT0.1S", "2026-06-15T12:00:00.05Z", "2026-06-15T12:00:00.1Z"},
+		{"R/2020-01-01T00:00:00Z/PT0.01S", "2026-06-15T12:00:00.005Z", "2026-06-15T12:00:00.01Z"},
+		{"R/1700-01-01T00:00:00Z/PT0.7S", "2026-06-15T12:00:00Z", "2026-06-15T12:00:00.7Z"},
+		{"R/2024-01-01T00:00:00Z/PT1,5H", "2024-01-01T00:00:00Z", "2024-01-01T01:30:00Z"},
+		{"R3/2024-01-01T00:00:00Z/2024-01-01T00:10:00Z", "2024-01-01T00:15:00Z", "2024-01-01T00:20:00Z"},
+		{"R3/2024-01-01T00:00:00Z/2024-01-01T00:10:00Z", "2024-01-01T00:20:00Z", ""},
+		{"R2/P1D/2024-01-10T00:00:00Z", "2024-01-01T00:00:00Z", "2024-01-09T00:00:00Z"},
+		{"R2/P1D/2024-01-10T00:00:00Z", "2024-01-09T00:00:00Z", "2024-01-10T00:00:00Z"},
+		{"R/2024-01-01/P1D", "2024-01-01T00:00:00Z", "2024-01-01T16:00:00Z"},
+		{"R/20240101T000000Z/PT1H", "2024-01-01T00:00:00Z", "2024-01-01T01:00:00Z"},
+		{"r/2024-01-01T09:00+08:00/p1d", "2024-01-01T02:00:00Z", "2024-01-02T01:00:00Z"},
+	}
+
+	parser := NewISO8601Parser(WithDefaultLocation(time.FixedZone("CST", 8*3600)))
+
+	for _, test := range tests {
+		sched, err := parser.Parse(test.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		expected := parseTime(test.expected)
+		if actual := sched.Next(parseTime(test.time)); !actual.Equal(expected) {
+			t.Errorf("Fail evaluating %s on %s: (expected) %s != %s (actual)", test.spec, test.time, expected, actual)
+			continue
+		}
+
+		// 反向计算应回到原时间之前的最后一次
+		if expected.IsZero() {
+			continue
+		}
+		rs := sched.(ReversibleSchedule)
+		if prev := rs.Prev(expected); !prev.IsZero() && !sched.Next(prev).Equal(expected) {
+			t.Errorf("Fail reversing %s on %s: %s", test.spec, expected, prev)
+		}
+	}
+
+	sched, err := parser.Parse("R/P1W")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	if next := sched.Next(now); next.Sub(now) > 7*24*time.Hour || next.Sub(now) < 7*24*time.Hour-time.Minute {
+		t.Errorf("unexpected next time for R/P1W: %s", next)
+	}
+
+	// 未指定开始时间时第一次执行也在解析之后，共执行 n 次
+	for _, spec := range []string{"R3/PT1S", "R3/PT0.3S"} {
+		sched, err := parser.Parse(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		runs := 0
+		for next := sched.Next(time.Now()); !next.IsZero(); next = sched.Next(next) {
+			runs++
+		}
+		if runs != 3 {
+			t.Errorf("expected %s runs 3 times, got %d", spec, runs)
+		}
+	}
+}
+
+func TestSchedRepeating(t *testing.T) {
+	sched := &SchedRepeating{
+		Start:  parseTime("2024-01-31T12:00:00Z"),
+		Period: ISODuration{Months: 1, Time: 90 * time.Minute},
+		Count:  3,
+	}
+
+	tests := []struct {
+		time string
+		next string
+		prev string
+	}{
+		{"2024-01-01T00:00:00Z", "2024-01-31T12:00:00Z", ""},
+		{"2024-01-31T12:00:00Z", "2024-02-29T13:30:00Z", ""},
+		{"2024-03-01T00:00:00Z", "2024-03-31T15:00:00Z", "2024-02-29T13:30:00Z"},
+		{"2024-03-31T15:00:00Z", "", "2024-02-29T13:30:00Z"},
+		{"2030-01-01T00:00:00Z", "", "2024-03-31T15:00:00Z"},
+	}
+
+	for _, test := range tests {
+		if actual := sched.Next(parseTime(test.time)); !actual.Equal(parseTime(test.next)) {
+			t.Errorf("Next(%s): (expected) %s != %s (actual)", test.time, test.next, actual)
+		}
+		if actual := sched.Prev(parseTime(test.time)); !actual.Equal(parseTime(test.prev)) {
+			t.Errorf("Prev(%s): (expected) %s != %s (actual)", test.time, test.prev, actual)
+		}
+	}
+
+	// 相差超过 time.Duration 范围时仍精确计算
+	subsecond := &SchedRepeating{Start: parseTime("1700-01-01T00:00:00Z"), Period: ISODuration{Time: 700 * time.Millisecond}}
+	if prev := subsecond.Prev(parseTime("2026-06-15T12:00:00Z")); !prev.Equal(parseTime("2026-06-15T11:59:59.3Z")) {
+		t.Errorf("unexpected prev time %s", prev)
+	}
+
+	if s := sched.String(); s != "R3/2024-01-31T12:00:00Z/P1MT1H30M" {
+		t.Errorf("unexpected string: %s", s)
+	}
+
+	parsed, err := NewISO8601Parser().Parse(sched.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := parsed.(*SchedRepeating).String(); s != sched.String() {
+		t.Errorf("unexpected round trip: %s", s)
+	}
+
+	if desc := Describe(sched, English); desc != "Every P1MT1H30M from 2024-01-31T12:00:00Z, 3 times" {
+		t.Errorf("unexpected description: %s", desc)
+	}
+}
+
+func TestISO8601ParseError(t *testing.T) {
+	tests := []struct {
+		spec   string
+		reason ParseReason
+		offset int
+	}{
+		{"", ReasonSyntax, 0},
+		{"P1D", ReasonSyntax, 0},
+		{"R5", ReasonFieldCount, 2},
+		{"R5/a/b/c", ReasonFieldCount, 8},
+		{"Rx/P1D", ReasonSyntax, 1},
+		{"R0/P1D", ReasonNeverFire, 0},
+		{"R/P", ReasonSyntax, 2},
+		{"R/P1H", ReasonSyntax, 4},
+		{"R/PT1D", ReasonSyntax, 5},
+		{"R/P1D2Y", ReasonSyntax, 6},
+		{"R/P1.5D", ReasonSyntax, 3},
+		{"R/PT", ReasonSyntax, 3},
+		{"R/P0D", ReasonOutOfRange, 2},
+		{"R/2024-13-01/P1D", ReasonSyntax, 2},
+		{"R/2024-01-01/PT1X", ReasonSyntax, 16},
+		{"R/P1D/tomorrow", ReasonSyntax, 6},
+		{"R/2024-01-02/2024-01-01", ReasonOutOfRange, 13},
+		{" R/P1DT", ReasonSyntax, 6},
+		{"R/2000-01-01T00:00:00Z/PT0.000000001S", ReasonOutOfRange, 23},
+	}
+
+	for _, test := range tests {
+		_, err := NewISO8601Parser().Parse(test.spec)
+
+		var pe *ParseError
+		if !errors.As(err, &pe) || pe.Reason != test.reason || pe.Offset != test.offset || pe.Expr != test.spec {
+			t.Errorf("unexpected error for %q: %v", test.spec, err)
+		}
+	}
+}
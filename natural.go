@@ -0,0 +1,1159 @@
+package beat
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 自然语言中的频率单位
+type naturalUnit uint8
+
+const (
+	unitNone naturalUnit = iota
+	unitSecond
+	unitMinute
+	unitHour
+	unitDay
+	unitWeek
+	unitMonth
+	unitYear
+)
+
+// 英文的频率单位，包括复数形式
+var englishUnits = map[string]naturalUnit{
+	"second": unitSecond, "seconds": unitSecond, "sec": unitSecond, "secs": unitSecond,
+	"minute": unitMinute, "minutes": unitMinute, "min": unitMinute, "mins": unitMinute,
+	"hour": unitHour, "hours": unitHour,
+	"day": unitDay, "days": unitDay,
+	"week": unitWeek, "weeks": unitWeek,
+	"month": unitMonth, "months": unitMonth,
+	"year": unitYear, "years": unitYear,
+}
+
+// 英文中表示频率的副词
+var englishAdverbs = map[string]naturalUnit{
+	"minutely": unitMinute, "hourly": unitHour, "daily": unitDay, "weekly": unitWeek,
+	"monthly": unitMonth, "yearly": unitYear, "annually": unitYear,
+}
+
+// 英文的数字
+var englishNumbers = map[string]int{
+	"one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6,
+	"seven": 7, "eight": 8, "nine": 9, "ten": 10, "eleven": 11, "twelve": 12,
+	"fifteen": 15, "twenty": 20, "thirty": 30,
+}
+
+// 英文的月份全称，缩写见 monthNames
+var englishMonths = map[string]int{
+	"january": 1, "february": 2, "march": 3, "april": 4, "may": 5, "june": 6,
+	"july": 7, "august": 8, "september": 9, "sept": 9, "october": 10, "november": 11, "december": 12,
+}
+
+// 英文中可以忽略的词
+var englishFillers = map[string]bool{
+	"and": true, "the": true, "of": true, "on": true, "in": true, "run": true, "at": true,
+}
+
+// 中文的频率单位，较长的在前
+var chineseUnits = []struct {
+	word string
+	unit naturalUnit
+}{
+	{"秒钟", unitSecond}, {"秒", unitSecond},
+	{"分钟", unitMinute}, {"分", unitMinute},
+	{"个小时", unitHour}, {"个钟头", unitHour}, {"小时", unitHour}, {"钟头", unitHour},
+	{"天", unitDay}, {"日", unitDay},
+	{"个星期", unitWeek}, {"个礼拜", unitWeek}, {"周", unitWeek}, {"星期", unitWeek}, {"礼拜", unitWeek},
+	{"个月", unitMonth}, {"月", unitMonth},
+	{"年", unitYear},
+}
+
+// 中文的星期，星期一到星期天使用1-7表示
+var chineseDows = map[rune]int{
+	'一': 1, '二': 2, '三': 3, '四': 4, '五': 5, '六': 6, '日': 7, '天': 7,
+}
+
+// 中文的数字
+var chineseDigits = map[rune]int{
+	'零': 0, '〇': 0, '一': 1, '二': 2, '两': 2, '三': 3, '四': 4,
+	'五': 5, '六': 6, '七': 7, '八': 8, '九': 9,
+}
+
+// 中文中可以忽略的词
+var chineseFillers = []string{
+	" ", "\t", "，", ",", "、", "。", "的", "和", "及", "与", "在", "从", "起", "之间", "期间",
+	"执行一次", "运行一次", "执行", "运行", "触发", "一次", "整",
+}
+
+// 中文中的时段，用于区分上午、下午和夜间
+var chinesePeriods = []struct {
+	word     string
+	meridiem string
+}{
+	{"凌晨", "am"}, {"清晨", "am"}, {"早晨", "am"}, {"早上", "am"}, {"上午", "am"},
+	{"中午", "noon"}, {"下午", "pm"}, {"傍晚", "pm"}, {"晚上", "night"}, {"夜里", "night"}, {"夜间", "night"},
+}
+
+// 自然语言中的时刻
+type naturalClock struct {
+	hour   int
+	minute int
+	offset int // 在表达式中的偏移
+}
+
+// 自然语言解析的中间结果
+type naturalSpec struct {
+	unit       naturalUnit // 频率单位
+	step       int         // 每隔几个单位
+	unitOffset int         // 频率在表达式中的偏移
+
+	months      uint64
+	doms        uint64
+	lastDays    uint64
+	lastWeekday bool
+	dows        uint64
+	lastDow     uint64
+	nthDow      uint64
+
+	times   []naturalClock // 每天执行的时刻
+	minute  int            // 每小时执行的分钟，-1 表示未指定
+	between []naturalClock // 时段的起止时刻，为空表示不限制
+}
+
+// 基于规则的自然语言解析器，支持英文和中文，表达式中含有汉字时按中文解析
+//
+// 英文支持以下形式的短语，不区分大小写，可以任意组合：
+//
+//	every [N|other] second(s)|minute(s)|hour(s)|day(s)|week(s)|month(s)|year(s)
+//	minutely、hourly、daily、weekly、monthly、yearly、annually
+//	[every|on] weekday(s)|weekend(s)|monday(s)|mon ...，可使用 monday to friday 表示范围
+//	on the 1st [and 15th]、on day 15、on the last day、on the last weekday
+//	on the first|second|third|fourth|fifth|last friday、on the 2nd friday
+//	in january [and july]、on march 1st
+//	at 9:30 [and 17:00]、at 9am、at 5:30 pm、at noon、at midnight、at minute 15
+//	between|from 8am and|to 6pm
+//
+// 中文支持以下形式的短语，数字可以使用阿拉伯数字或汉字：
+//
+//	每[隔][N][个]秒|分钟|小时|天|周|月|年
+//	工作日、周末、每周一三五、周一至周五、星期六、礼拜天
+//	1号、15日、最后一天、最后一个工作日、第二个周五、最后一个周五
+//	3月、3月1日
+//	早上8点、下午3点半、9点15分、21:30、中午12点、零点、每小时15分
+//	8点到18点、从9点至17点之间
+//
+// 时段仅用于每小时或更短的频率，整点频率包含结束时刻，更短的频率在结束时刻所在小时之前结束；
+// 每周未指定星期时为周一，每月未指定日时为 1 日，每年未指定月和日时为 1 月 1 日，未指定时刻时为 0 点。
+// 能够表示为 SchedTime 时返回 SchedTime，分钟不同的多个时刻 (如 9:30 和 17:00) 返回 SchedTime 的 SchedUnion；
+// 无法对齐到日历的固定间隔 (如每 90 分钟) 返回 SchedEvery；
+// 每 N 天、每 N 周返回以解析当天或本周一零点 (及各时刻) 为起点、按日历日重复的 SchedRepeating，不能再限制日期，
+// 其相位取决于解析的日期，重新解析 (如重启后) 可能与之前相差若干天；
+// 无法识别或不支持的短语返回 ParseError
+type NaturalParser struct {
+	location *time.Location // 缺省时区
+}
+
+// 创建自然语言解析器，opts 中仅 WithDefaultLocation 有效
+func NewNaturalParser(opts ...parserOption) *NaturalParser {
+	return &NaturalParser{location: NewParser(opts...).defaultLoction}
+}
+
+// 解析自然语言描述的定时
+func (p *NaturalParser) Parse(exp string) (Schedule, error) {
+	spec := &naturalSpec{minute: -1}
+
+	var err error
+	if strings.IndexFunc(exp, func(r rune) bool { return unicode.Is(unicode.Han, r) }) >= 0 {
+		err = parseChinese(exp, spec)
+	} else {
+		err = parseEnglish(exp, spec)
+	}
+	if err != nil {
+		return nil, locateError(err, exp, 0, -1, 0)
+	}
+
+	sched, err := spec.compile(p.location)
+	if err != nil {
+		return nil, locateError(err, exp, 0, -1, 0)
+	}
+
+	return sched, nil
+}
+
+// 设置频率，已设置不同的频率时返回错误
+func (spec *naturalSpec) setUnit(unit naturalUnit, step, offset int) error {
+	if step <= 0 {
+		return newParseError(ReasonStep, offset, "bad repetition '%d'", step)
+	}
+	if spec.unit != unitNone && (spec.unit != unit || spec.step != step) {
+		return newParseError(ReasonSyntax, offset, "conflicting frequency")
+	}
+
+	spec.unit, spec.step, spec.unitOffset = unit, step, offset
+	return nil
+}
+
+// 添加星期范围，范围可以跨过周末
+func (spec *naturalSpec) addDows(start, end int) {
+	for dow := start; ; dow = dow%7 + 1 {
+		spec.dows |= 1 << dow
+		if dow == end {
+			break
+		}
+	}
+}
+
+// 添加当月第 k 个星期几
+func (spec *naturalSpec) addNthDow(k, dow, offset int) error {
+	if k < 1 || k > 5 {
+		return newParseError(ReasonOutOfRange, offset, "out of range: %d", k)
+	}
+
+	spec.nthDow |= 1 << (k*8 + dow)
+	return nil
+}
+
+// 添加日
+func (spec *naturalSpec) addDom(day, offset int) error {
+	if day < 1 || day > 31 {
+		return newParseError(ReasonOutOfRange, offset, "out of range: %d", day)
+	}
+
+	spec.doms |= 1 << day
+	return nil
+}
+
+// 添加月
+func (spec *naturalSpec) addMonth(month, offset int) error {
+	if month < 1 || month > 12 {
+		return newParseError(ReasonOutOfRange, offset, "out of range: %d", month)
+	}
+
+	spec.months |= 1 << month
+	return nil
+}
+
+// 设置时段的结束时刻，起始时刻为最后一个时刻
+func (spec *naturalSpec) setBetween(from, to naturalClock) error {
+	if len(spec.between) > 0 {
+		return newParseError(ReasonSyntax, from.offset, "duplicate time range")
+	}
+
+	spec.between = []naturalClock{from, to}
+	return nil
+}
+
+// 判断是否限制了日期
+func (spec *naturalSpec) hasDays() bool {
+	return spec.months|spec.doms|spec.lastDays|spec.dows|spec.lastDow|spec.nthDow != 0 || spec.lastWeekday
+}
+
+// 将解析结果转换为定时
+func (spec *naturalSpec) compile(location *time.Location) (Schedule, error) {
+	if spec.unit == unitNone && !spec.hasDays() && len(spec.times) == 0 {
+		return nil, newParseError(ReasonFieldCount, 0, "missing frequency, day or time")
+	}
+
+	// 每天执行的分钟及对应的小时
+	var minutes []int
+	var hours []uint64
+
+	st := &SchedTime{
+		Month:    Month.all(),
+		Dom:      Dom.all(),
+		Dow:      Dow.all(),
+		Hour:     1,
+		Minute:   1,
+		Second:   1,
+		location: location,
+	}
+
+	switch spec.unit {
+	case unitSecond, unitMinute, unitHour:
+		if len(spec.times) > 0 {
+			return nil, newParseError(ReasonSyntax, spec.times[0].offset, "time of day conflicts with the frequency")
+		}
+		if spec.minute >= 0 && spec.unit != unitHour {
+			return nil, newParseError(ReasonSyntax, spec.unitOffset, "minute of hour conflicts with the frequency")
+		}
+
+		base := map[naturalUnit]int{unitSecond: 60, unitMinute: 60, unitHour: 24}[spec.unit]
+		if base%spec.step != 0 {
+			// 无法对齐到日历时使用固定间隔
+			if spec.hasDays() || len(spec.between) > 0 || spec.minute >= 0 {
+				return nil, newParseError(ReasonStep, spec.unitOffset, "every %d cannot be aligned with other restrictions", spec.step)
+			}
+			unit := map[naturalUnit]time.Duration{unitSecond: time.Second, unitMinute: time.Minute, unitHour: time.Hour}[spec.unit]
+			return &SchedEvery{Interval: time.Duration(spec.step) * unit}, nil
+		}
+
+		steps := uint64(0)
+		for v := 0; v < base; v += spec.step {
+			steps |= 1 << v
+		}
+
+		hours, err := spec.hours()
+		if err != nil {
+			return nil, err
+		}
+
+		switch spec.unit {
+		case unitSecond:
+			st.Second, st.Minute, st.Hour = steps, Minute.all(), hours
+		case unitMinute:
+			st.Minute, st.Hour = steps, hours
+		case unitHour:
+			st.Hour = hours
+			if spec.minute >= 0 {
+				st.Minute = 1 << spec.minute
+			}
+		}
+
+	default:
+		if len(spec.between) > 0 {
+			return nil, newParseError(ReasonSyntax, spec.between[0].offset, "time range requires an hourly or shorter frequency")
+		}
+		if spec.minute >= 0 {
+			return nil, newParseError(ReasonSyntax, 0, "minute of hour requires an hourly frequency")
+		}
+
+		if spec.step > 1 {
+			switch {
+			case spec.unit == unitMonth && 12%spec.step == 0:
+				st.Month = 0
+				for month := 1; month <= 12; month += spec.step {
+					st.Month |= 1 << month
+				}
+			case (spec.unit == unitDay || spec.unit == unitWeek) && !spec.hasDays():
+				return spec.interval(location), nil
+			default:
+				return nil, newParseError(ReasonStep, spec.unitOffset, "every %d cannot be aligned with the calendar", spec.step)
+			}
+		}
+
+		// 分钟相同的时刻合并为一个 SchedTime
+		for _, clock := range spec.times {
+			if i := slices.Index(minutes, clock.minute); i >= 0 {
+				hours[i] |= 1 << clock.hour
+			} else {
+				minutes, hours = append(minutes, clock.minute), append(hours, 1<<clock.hour)
+			}
+		}
+		if len(minutes) > 0 {
+			st.Hour, st.Minute = hours[0], 1<<minutes[0]
+		}
+
+		// 未指定日期时使用每周、每月或每年的第一天
+		days := spec.doms|spec.lastDays|spec.dows|spec.lastDow|spec.nthDow != 0 || spec.lastWeekday
+		switch {
+		case spec.unit == unitWeek && !days:
+			spec.dows = 1 << 1
+		case spec.unit == unitMonth && !days:
+			spec.doms = 1 << 1
+		case spec.unit == unitYear && !days:
+			spec.doms = 1 << 1
+			if spec.months == 0 {
+				spec.months = 1 << 1
+			}
+		}
+	}
+
+	if spec.months != 0 {
+		st.Month = spec.months
+	}
+	if spec.doms|spec.lastDays != 0 || spec.lastWeekday {
+		st.Dom = spec.doms
+		st.LastDays = spec.lastDays
+		st.LastWeekday = spec.lastWeekday
+	}
+	if spec.dows|spec.lastDow|spec.nthDow != 0 {
+		st.Dow = spec.dows
+		st.LastDow = spec.lastDow
+		st.NthDow = spec.nthDow
+	}
+
+	if err := st.Validate(); errors.Is(err, ErrNeverFire) {
+		pe := newParseError(ReasonNeverFire, 0, "%s", err)
+		pe.Err = err
+		return nil, pe
+	}
+
+	// 分钟不同的时刻使用多个 SchedTime 的并集
+	if len(minutes) > 1 {
+		schedules := make([]Schedule, len(minutes))
+		for i := range minutes {
+			clone := *st
+			clone.Hour, clone.Minute = hours[i], 1<<minutes[i]
+			schedules[i] = &clone
+		}
+		return Union(schedules[0], schedules[1:]...), nil
+	}
+
+	return st, nil
+}
+
+// 将每 N 天或每 N 周转换为按日历日重复的定时，以解析当天或本周一的零点加上各时刻作为第一次执行的时间
+func (spec *naturalSpec) interval(location *time.Location) Schedule {
+	now := time.Now().In(location)
+	year, month, day := now.Date()
+	period := ISODuration{Days: spec.step}
+	if spec.unit == unitWeek {
+		day -= weekday(now) - 1
+		period.Days *= 7
+	}
+
+	if len(spec.times) == 0 {
+		return &SchedRepeating{Start: time.Date(year, month, day, 0, 0, 0, 0, location), Period: period}
+	}
+
+	schedules := make([]Schedule, len(spec.times))
+	for i, clock := range spec.times {
+		schedules[i] = &SchedRepeating{Start: time.Date(year, month, day, clock.hour, clock.minute, 0, 0, location), Period: period}
+	}
+	if len(schedules) == 1 {
+		return schedules[0]
+	}
+	return Union(schedules[0], schedules[1:]...)
+}
+
+// 获取频率为每小时或更短时的小时，整点频率包含结束时刻
+func (spec *naturalSpec) hours() (uint64, error) {
+	if len(spec.between) == 0 {
+		if spec.unit == unitHour {
+			hours := uint64(0)
+			for h := 0; h < 24; h += spec.step {
+				hours |= 1 << h
+			}
+			return hours, nil
+		}
+		return Hour.all(), nil
+	}
+
+	from, to := spec.between[0], spec.between[1]
+	if from.minute != 0 || to.minute != 0 {
+		return 0, newParseError(ReasonSyntax, from.offset, "time range must use whole hours")
+	}
+
+	// 时段可以跨过午夜
+	span := (to.hour - from.hour + 24) % 24
+	if span == 0 {
+		return 0, newParseError(ReasonOutOfRange, to.offset, "empty time range")
+	}
+
+	hours := uint64(0)
+	if spec.unit == unitHour {
+		for h := 0; h <= span; h += spec.step {
+			hours |= 1 << ((from.hour + h) % 24)
+		}
+	} else {
+		for h := 0; h < span; h++ {
+			hours |= 1 << ((from.hour + h) % 24)
+		}
+	}
+
+	return hours, nil
+}
+
+// 英文表达式中的单词
+type englishToken struct {
+	text   string // 小写的单词
+	offset int    // 在表达式中的偏移
+}
+
+// 将英文表达式按空白和逗号拆分为单词，- 单独作为一个单词
+func tokenizeEnglish(exp string) []englishToken {
+	var tokens []englishToken
+
+	start := -1
+	for i := 0; i <= len(exp); i++ {
+		if i < len(exp) && !strings.ContainsRune(" \t\n,-", rune(exp[i])) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+
+		if start >= 0 {
+			tokens = append(tokens, englishToken{strings.ToLower(exp[start:i]), start})
+			start = -1
+		}
+		if i < len(exp) && exp[i] == '-' {
+			tokens = append(tokens, englishToken{"-", i})
+		}
+	}
+
+	return tokens
+}
+
+// 解析英文表达式
+func parseEnglish(exp string, spec *naturalSpec) error {
+	tokens := tokenizeEnglish(exp)
+
+	// 获取第 i 个单词，超出范围时返回空单词，偏移为表达式末尾
+	at := func(i int) englishToken {
+		if i < len(tokens) {
+			return tokens[i]
+		}
+		return englishToken{"", len(exp)}
+	}
+
+	inTime := false
+	for i := 0; i < len(tokens); {
+		tok := tokens[i]
+		word := tok.text
+
+		clock, next, ok, err := parseEnglishClock(tokens, i, inTime)
+		if err != nil {
+			return err
+		}
+		if ok {
+			spec.times = append(spec.times, clock)
+			i = next
+			continue
+		}
+
+		if word != "and" {
+			inTime = word == "at"
+		}
+
+		switch {
+		case word == "every" || word == "each":
+			i++
+			step, unitAt := 1, i
+			if n, ok := englishNumber(at(i).text); ok {
+				step, unitAt = n, i+1
+			} else if at(i).text == "other" {
+				step, unitAt = 2, i+1
+			}
+
+			unit, ok := englishUnits[at(unitAt).text]
+			if !ok {
+				if unitAt != i {
+					return newParseError(ReasonSyntax, at(unitAt).offset, "expected a unit after '%s'", at(i).text)
+				}
+				// every monday、every weekday 等由后续单词处理
+				continue
+			}
+
+			if err := spec.setUnit(unit, step, tok.offset); err != nil {
+				return err
+			}
+			i = unitAt + 1
+
+		case englishAdverbs[word] != unitNone:
+			if err := spec.setUnit(englishAdverbs[word], 1, tok.offset); err != nil {
+				return err
+			}
+			i++
+
+		case word == "weekday" || word == "weekdays":
+			spec.addDows(1, 5)
+			i++
+
+		case word == "weekend" || word == "weekends":
+			spec.addDows(6, 7)
+			i++
+
+		case englishDow(word) > 0:
+			start, end := englishDow(word), englishDow(word)
+			i++
+			if sep := at(i).text; (sep == "to" || sep == "through" || sep == "thru" || sep == "-") && englishDow(at(i+1).text) > 0 {
+				end = englishDow(at(i + 1).text)
+				i += 2
+			}
+			spec.addDows(start, end)
+
+		case englishMonth(word) > 0:
+			if err := spec.addMonth(englishMonth(word), tok.offset); err != nil {
+				return err
+			}
+			i++
+			// march 1st、jan 15
+			if day, ok := englishDay(at(i).text); ok {
+				if err := spec.addDom(day, at(i).offset); err != nil {
+					return err
+				}
+				i++
+			}
+
+		case word == "last":
+			switch next := at(i + 1).text; {
+			case next == "day":
+				spec.lastDays |= 1
+			case next == "weekday":
+				spec.lastWeekday = true
+			case englishDow(next) > 0:
+				spec.lastDow |= 1 << englishDow(next)
+			default:
+				return newParseError(ReasonSyntax, at(i+1).offset, "expected day, weekday or a day of week after 'last'")
+			}
+			i += 2
+
+		case slices.Index(englishOrdinals, word) > 0 && englishDow(at(i+1).text) > 0:
+			if err := spec.addNthDow(slices.Index(englishOrdinals, word), englishDow(at(i+1).text), tok.offset); err != nil {
+				return err
+			}
+			i += 2
+
+		case word == "first" && at(i+1).text == "day":
+			spec.doms |= 1 << 1
+			i += 2
+
+		case isEnglishOrdinal(word):
+			day, _ := englishDay(word)
+			if dow := englishDow(at(i + 1).text); dow > 0 {
+				if err := spec.addNthDow(day, dow, tok.offset); err != nil {
+					return err
+				}
+				i += 2
+				continue
+			}
+
+			if err := spec.addDom(day, tok.offset); err != nil {
+				return err
+			}
+			i++
+
+		case word == "day":
+			day, ok := englishDay(at(i + 1).text)
+			if !ok {
+				return newParseError(ReasonSyntax, at(i+1).offset, "expected a day of month")
+			}
+			if err := spec.addDom(day, at(i+1).offset); err != nil {
+				return err
+			}
+			i += 2
+
+		case word == "minute":
+			minute, err := strconv.Atoi(at(i + 1).text)
+			if err != nil {
+				return newParseError(ReasonSyntax, at(i+1).offset, "expected a minute")
+			}
+			if minute < 0 || minute > 59 {
+				return newParseError(ReasonOutOfRange, at(i+1).offset, "out of range: %d", minute)
+			}
+			spec.minute = minute
+			i += 2
+
+		case word == "from" && (englishDow(at(i+1).text) > 0 || at(i+1).text == "weekday"):
+			// from monday to friday
+			i++
+
+		case word == "between" || word == "from":
+			from, next, ok, err := parseEnglishClock(tokens, i+1, true)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return newParseError(ReasonSyntax, at(i+1).offset, "expected a time after '%s'", word)
+			}
+
+			if sep := at(next).text; sep != "and" && sep != "to" && sep != "until" && sep != "through" && sep != "-" {
+				return newParseError(ReasonSyntax, at(next).offset, "expected 'and' or 'to'")
+			}
+
+			to, end, ok, err := parseEnglishClock(tokens, next+1, true)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return newParseError(ReasonSyntax, at(next+1).offset, "expected a time")
+			}
+
+			if err := spec.setBetween(from, to); err != nil {
+				return err
+			}
+			i = end
+
+		case englishFillers[word]:
+			i++
+
+		default:
+			return newParseError(ReasonSyntax, tok.offset, "unsupported phrase '%s'", word)
+		}
+	}
+
+	return nil
+}
+
+// 解析英文的时刻，如 9:30、9am、5:30 pm、noon，bare 为 true 时不带 am/pm 的整数也作为时刻
+//
+// 返回时刻及下一个单词的位置，ok 为 false 表示不是时刻
+func parseEnglishClock(tokens []englishToken, i int, bare bool) (clock naturalClock, next int, ok bool, err error) {
+	if i >= len(tokens) {
+		return clock, i, false, nil
+	}
+
+	tok := tokens[i]
+	clock.offset = tok.offset
+
+	switch tok.text {
+	case "noon", "midday":
+		clock.hour = 12
+		return clock, i + 1, true, nil
+	case "midnight":
+		return clock, i + 1, true, nil
+	}
+
+	body, meridiem := tok.text, ""
+	for _, suffix := range []string{"am", "pm", "a.m.", "p.m."} {
+		if value, found := strings.CutSuffix(tok.text, suffix); found && value != "" {
+			body, meridiem = value, suffix[:1]
+			break
+		}
+	}
+	next = i + 1
+	if meridiem == "" && next < len(tokens) {
+		switch tokens[next].text {
+		case "am", "a.m.", "pm", "p.m.":
+			meridiem = tokens[next].text[:1]
+			next++
+		}
+	}
+
+	hourValue, minuteValue, hasColon := strings.Cut(body, ":")
+	if !hasColon && meridiem == "" && !bare {
+		return clock, i, false, nil
+	}
+
+	hour, err := strconv.Atoi(hourValue)
+	if err != nil {
+		if hasColon || meridiem != "" {
+			return clock, i, false, newParseError(ReasonSyntax, tok.offset, "bad time '%s'", tok.text)
+		}
+		return clock, i, false, nil
+	}
+
+	minute := 0
+	if hasColon {
+		minute, err = strconv.Atoi(minuteValue)
+		if err != nil || len(minuteValue) != 2 {
+			return clock, i, false, newParseError(ReasonSyntax, tok.offset+len(hourValue)+1, "bad minute '%s'", minuteValue)
+		}
+	}
+
+	switch {
+	case meridiem != "" && (hour < 1 || hour > 12), hour < 0 || hour > 23:
+		return clock, i, false, newParseError(ReasonOutOfRange, tok.offset, "out of range: %s", tok.text)
+	case minute > 59:
+		return clock, i, false, newParseError(ReasonOutOfRange, tok.offset+len(hourValue)+1, "out of range: %s", tok.text)
+	}
+
+	switch {
+	case meridiem == "a" && hour == 12:
+		hour = 0
+	case meridiem == "p" && hour < 12:
+		hour += 12
+	}
+
+	if next < len(tokens) && tokens[next].text == "o'clock" {
+		next++
+	}
+
+	clock.hour, clock.minute = hour, minute
+	return clock, next, true, nil
+}
+
+// 解析英文的数字，如 2、two
+func englishNumber(word string) (int, bool) {
+	if n, ok := englishNumbers[word]; ok {
+		return n, true
+	}
+
+	n, err := strconv.Atoi(word)
+	return n, err == nil
+}
+
+// 获取英文星期名称对应的星期，支持复数形式，无效时返回 0
+func englishDow(word string) int {
+	if dow, ok := systemdDowNames[word]; ok {
+		return dow
+	}
+
+	return systemdDowNames[strings.TrimSuffix(word, "s")]
+}
+
+// 获取英文月份名称对应的月份，无效时返回 0
+func englishMonth(word string) int {
+	if month, ok := englishMonths[word]; ok {
+		return month
+	}
+
+	return monthNames[word]
+}
+
+// 判断是否为数字序数词，如 1st、22nd
+func isEnglishOrdinal(word string) bool {
+	if len(word) < 3 {
+		return false
+	}
+
+	_, err := strconv.Atoi(word[:len(word)-2])
+	return err == nil && slices.Contains([]string{"st", "nd", "rd", "th"}, word[len(word)-2:])
+}
+
+// 解析日，支持数字和数字序数词，如 1、1st
+func englishDay(word string) (int, bool) {
+	if isEnglishOrdinal(word) {
+		word = word[:len(word)-2]
+	}
+
+	day, err := strconv.Atoi(word)
+	return day, err == nil
+}
+
+// 中文表达式的扫描器，pos 为当前的字节偏移
+type chineseScanner struct {
+	exp string
+	pos int
+}
+
+// 若当前位置以 words 中的某个词开头则跳过该词并返回 true
+func (s *chineseScanner) accept(words ...string) bool {
+	for _, word := range words {
+		if strings.HasPrefix(s.exp[s.pos:], word) {
+			s.pos += len(word)
+			return true
+		}
+	}
+
+	return false
+}
+
+// 读取阿拉伯数字或汉字数字，如 15、十五、二十三
+func (s *chineseScanner) number() (int, bool) {
+	start := s.pos
+	for s.pos < len(s.exp) && s.exp[s.pos] >= '0' && s.exp[s.pos] <= '9' {
+		s.pos++
+	}
+	if s.pos > start {
+		n, err := strconv.Atoi(s.exp[start:s.pos])
+		return n, err == nil
+	}
+
+	n, tens, digits := 0, -1, 0
+	for s.pos < len(s.exp) {
+		r, size := utf8.DecodeRuneInString(s.exp[s.pos:])
+		if r == '十' && tens < 0 {
+			tens = n
+			if digits == 0 {
+				tens = 1
+			}
+			n = 0
+		} else if d, ok := chineseDigits[r]; ok {
+			n = n*10 + d
+			digits++
+		} else {
+			break
+		}
+		s.pos += size
+	}
+
+	if s.pos == start {
+		return 0, false
+	}
+	if tens >= 0 {
+		n += tens * 10
+	}
+	return n, true
+}
+
+// 读取星期，如 一、日、天、3
+func (s *chineseScanner) dow() (int, bool) {
+	r, size := utf8.DecodeRuneInString(s.exp[s.pos:])
+	if dow, ok := chineseDows[r]; ok {
+		s.pos += size
+		return dow, true
+	}
+
+	return 0, false
+}
+
+// 读取星期列表或范围，如 一三五、一至五、一到周五
+func (s *chineseScanner) dows(spec *naturalSpec) error {
+	for first := true; ; first = false {
+		start, ok := s.dow()
+		if !ok {
+			if first {
+				return newParseError(ReasonSyntax, s.pos, "expected a day of week")
+			}
+			return nil
+		}
+
+		end, save := start, s.pos
+		if s.accept("至", "到", "-", "~") {
+			s.accept("周", "星期", "礼拜")
+			if end, ok = s.dow(); !ok {
+				end, s.pos = start, save
+			}
+		}
+
+		spec.addDows(start, end)
+	}
+}
+
+// 解析中文表达式
+func parseChinese(exp string, spec *naturalSpec) error {
+	s := &chineseScanner{exp: exp}
+
+	meridiem := ""
+	for s.pos < len(s.exp) {
+		start := s.pos
+
+		switch {
+		case s.accept(chineseFillers...):
+
+		case s.accept("每隔", "每"):
+			s.accept("个")
+			step, hasStep := s.number()
+			if !hasStep {
+				step = 1
+			}
+
+			unit := unitNone
+			for _, u := range chineseUnits {
+				if s.accept(u.word) {
+					unit = u.unit
+					break
+				}
+			}
+			if unit == unitNone {
+				if hasStep {
+					return newParseError(ReasonSyntax, s.pos, "expected a unit")
+				}
+				// 每个工作日、每周末等由后续的词处理
+				continue
+			}
+
+			// 每周一三五、每周末
+			if unit == unitWeek {
+				if s.accept("末") {
+					spec.addDows(6, 7)
+				} else if _, ok := chineseDows[firstRune(s.exp[s.pos:])]; ok {
+					if err := s.dows(spec); err != nil {
+						return err
+					}
+				}
+			}
+
+			if err := spec.setUnit(unit, step, start); err != nil {
+				return err
+			}
+
+		case s.accept("工作日"):
+			spec.addDows(1, 5)
+
+		case s.accept("周末"):
+			spec.addDows(6, 7)
+
+		case s.accept("周", "星期", "礼拜"):
+			if err := s.dows(spec); err != nil {
+				return err
+			}
+
+		case s.accept("最后一天"):
+			spec.lastDays |= 1
+
+		case s.accept("最后一个工作日"):
+			spec.lastWeekday = true
+
+		case s.accept("最后一个", "最后"):
+			if !s.accept("周", "星期", "礼拜") {
+				return newParseError(ReasonSyntax, s.pos, "expected a day of week")
+			}
+			dow, ok := s.dow()
+			if !ok {
+				return newParseError(ReasonSyntax, s.pos, "expected a day of week")
+			}
+			spec.lastDow |= 1 << dow
+
+		case s.accept("第"):
+			k, ok := s.number()
+			if !ok {
+				return newParseError(ReasonSyntax, s.pos, "expected a number")
+			}
+			s.accept("个")
+
+			if s.accept("天") {
+				if err := spec.addDom(k, start); err != nil {
+					return err
+				}
+				break
+			}
+
+			if !s.accept("周", "星期", "礼拜") {
+				return newParseError(ReasonSyntax, s.pos, "expected a day of week")
+			}
+			dow, ok := s.dow()
+			if !ok {
+				return newParseError(ReasonSyntax, s.pos, "expected a day of week")
+			}
+			if err := spec.addNthDow(k, dow, start); err != nil {
+				return err
+			}
+
+		case s.accept("零点", "午夜"):
+			spec.times = append(spec.times, naturalClock{offset: start})
+
+		case s.accept("到", "至", "~", "-"):
+			// 时段的起始时刻为上一个时刻
+			if len(spec.times) == 0 {
+				return newParseError(ReasonSyntax, start, "missing start of time range")
+			}
+
+			// 未指定时段时沿用起始时刻的时段
+			m := meridiem
+			for _, period := range chinesePeriods {
+				if s.accept(period.word) {
+					m = period.meridiem
+					break
+				}
+			}
+
+			to, err := s.clock(m)
+			if err != nil {
+				return err
+			}
+
+			from := spec.times[len(spec.times)-1]
+			spec.times = spec.times[:len(spec.times)-1]
+			if err := spec.setBetween(from, to); err != nil {
+				return err
+			}
+
+		default:
+			matched := false
+			for _, period := range chinesePeriods {
+				if s.accept(period.word) {
+					meridiem, matched = period.meridiem, true
+					break
+				}
+			}
+			if matched {
+				continue
+			}
+
+			n, ok := s.number()
+			if !ok {
+				r, _ := utf8.DecodeRuneInString(s.exp[s.pos:])
+				return newParseError(ReasonSyntax, start, "unsupported phrase '%c'", r)
+			}
+
+			switch {
+			case s.accept("月"):
+				if err := spec.addMonth(n, start); err != nil {
+					return err
+				}
+
+			case s.accept("日", "号"):
+				if err := spec.addDom(n, start); err != nil {
+					return err
+				}
+
+			case s.accept("分钟", "分"):
+				// 每小时15分
+				if n > 59 {
+					return newParseError(ReasonOutOfRange, start, "out of range: %d", n)
+				}
+				spec.minute = n
+
+			default:
+				s.pos = start
+				clock, err := s.clock(meridiem)
+				if err != nil {
+					return err
+				}
+				spec.times = append(spec.times, clock)
+			}
+		}
+	}
+
+	return nil
+}
+
+// 读取时刻，如 8点、8点半、9点15分、21:30，meridiem 为之前的时段
+func (s *chineseScanner) clock(meridiem string) (naturalClock, error) {
+	clock := naturalClock{offset: s.pos}
+
+	if s.accept("零点", "午夜") {
+		return clock, nil
+	}
+
+	hour, ok := s.number()
+	if !ok {
+		return clock, newParseError(ReasonSyntax, s.pos, "expected a time")
+	}
+
+	minute := 0
+	switch {
+	case s.accept(":", "："):
+		start := s.pos
+		minute, ok = s.number()
+		if !ok || s.pos-start != 2 {
+			return clock, newParseError(ReasonSyntax, start, "bad minute")
+		}
+
+	case s.accept("点", "时"):
+		start := s.pos
+		switch {
+		case s.accept("半"):
+			minute = 30
+		case s.accept("一刻"):
+			minute = 15
+		case s.accept("三刻"):
+			minute = 45
+		default:
+			if minute, ok = s.number(); ok {
+				s.accept("分钟", "分")
+			} else {
+				minute = 0
+			}
+		}
+		if minute > 59 {
+			return clock, newParseError(ReasonOutOfRange, start, "out of range: %d", minute)
+		}
+
+	default:
+		return clock, newParseError(ReasonSyntax, s.pos, "expected '点' or ':'")
+	}
+
+	switch meridiem {
+	case "am":
+		if hour == 12 {
+			hour = 0
+		}
+	case "noon":
+		if hour < 6 {
+			hour += 12
+		}
+	case "pm":
+		if hour < 12 {
+			hour += 12
+		}
+	case "night":
+		switch {
+		case hour == 12: // 晚上12点即午夜
+			hour = 0
+		case hour < 12:
+			hour += 12
+		}
+	}
+
+	if hour > 23 || minute > 59 {
+		return clock, newParseError(ReasonOutOfRange, clock.offset, "out of range: %d:%02d", hour, minute)
+	}
+
+	clock.hour, clock.minute = hour, minute
+	return clock, nil
+}
+
+// 获取字符串的第一个字符
+func firstRune(s string) rune {
+	r, _ := utf8.DecodeRuneInString(s)
+	return r
+}
@@ -0,0 +1,177 @@
+package beat
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNaturalParser(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected string
+	}{
+		{"every weekday at 9:30", "TZ=UTC * * 1-5 9 30 0"},
+		{"every 2 hours between 8am and 6pm", "TZ=UTC * * * 8-18/2 0 0"},
+		{"every 15 minutes from 9am to 5pm", "TZ=UTC * * * 9-16 */15 0"},
+		{"Every 5 seconds", "TZ=UTC * * * * * */5"},
+		{"every hour at minute 15", "TZ=UTC * * * * 15 0"},
+		{"every 90 minutes", "@every 1h30m0s"},
+		{"daily", "TZ=UTC * * * 0 0 0"},
+		{"every day at midnight", "TZ=UTC * * * 0 0 0"},
+		{"every monday", "TZ=UTC * * 1 0 0 0"},
+		{"mon to fri at 8:00 am", "TZ=UTC * * 1-5 8 0 0"},
+		{"every weekend at 10am", "TZ=UTC * * 6,7 10 0 0"},
+		{"at 9 and 17", "TZ=UTC * * * 9,17 0 0"},
+		{"at 12am and 12pm", "TZ=UTC * * * 0,12 0 0"},
+		{"every month", "TZ=UTC * 1 * 0 0 0"},
+		{"every 3 months", "TZ=UTC */3 1 * 0 0 0"},
+		{"on the 1st and 15th at noon", "TZ=UTC * 1,15 * 12 0 0"},
+		{"on the last day at 11:30 pm", "TZ=UTC * L * 23 30 0"},
+		{"on the last weekday", "TZ=UTC * LW * 0 0 0"},
+		{"on the last friday at 6pm", "TZ=UTC * * 5L 18 0 0"},
+		{"on the first monday of every month at 10:00", "TZ=UTC * * 1#1 10 0 0"},
+		{"on the 2nd tuesday", "TZ=UTC * * 2#2 0 0 0"},
+		{"every year on march 1st", "TZ=UTC 3 1 * 0 0 0"},
+		{"every year", "TZ=UTC 1 1 * 0 0 0"},
+		{"in january and july on day 10", "TZ=UTC 1,7 10 * 0 0 0"},
+		{"每天早上8点", "TZ=UTC * * * 8 0 0"},
+		{"工作日早上九点", "TZ=UTC * * 1-5 9 0 0"},
+		{"周一至周五9:30", "TZ=UTC * * 1-5 9 30 0"},
+		{"每周一三五下午3点半", "TZ=UTC * * 1-5/2 15 30 0"},
+		{"每周末上午10点", "TZ=UTC * * 6,7 10 0 0"},
+		{"每2小时，8点到18点之间", "TZ=UTC * * * 8-18/2 0 0"},
+		{"下午1点到5点每小时", "TZ=UTC * * * 13-17 0 0"},
+		{"每隔十五分钟", "TZ=UTC * * * * */15 0"},
+		{"每小时15分", "TZ=UTC * * * * 15 0"},
+		{"每月1号和15号中午12点", "TZ=UTC * 1,15 * 12 0 0"},
+		{"每月最后一天23点", "TZ=UTC * L * 23 0 0"},
+		{"每月第二个周五晚上8点", "TZ=UTC * * 5#2 20 0 0"},
+		{"每年3月1日零点", "TZ=UTC 3 1 * 0 0 0"},
+		{"每天21：45", "TZ=UTC * * * 21 45 0"},
+		{"每天晚上12点", "TZ=UTC * * * 0 0 0"},
+		{"每天早上8点和晚上8点半", "TZ=UTC * * * 8 0 0 | TZ=UTC * * * 20 30 0"},
+		{"at 9:30 and 17:00", "TZ=UTC * * * 9 30 0 | TZ=UTC * * * 17 0 0"},
+		{"on weekdays at 9, 12 and 17:30", "TZ=UTC * * 1-5 9,12 0 0 | TZ=UTC * * 1-5 17 30 0"},
+	}
+
+	parser := NewNaturalParser(WithDefaultLocation(time.UTC))
+
+	for _, test := range tests {
+		sched, err := parser.Parse(test.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		if actual := sched.(interface{ String() string }).String(); actual != test.expected {
+			t.Errorf("Fail parsing %s: (expected) %s != %s (actual)", test.spec, test.expected, actual)
+		}
+	}
+}
+
+func TestNaturalInterval(t *testing.T) {
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	monday := today.AddDate(0, 0, 1-weekday(today))
+
+	tests := []struct {
+		spec     string
+		expected []time.Time // 从起点所在日的零点起依次执行的时间
+	}{
+		{"every 2 days", []time.Time{today, today.Add(48 * time.Hour), today.Add(96 * time.Hour)}},
+		{"每3天", []time.Time{today, today.Add(72 * time.Hour), today.Add(144 * time.Hour)}},
+		{"every other week", []time.Time{monday, monday.Add(14 * 24 * time.Hour)}},
+		{"每两周", []time.Time{monday, monday.Add(14 * 24 * time.Hour)}},
+		{"every 2 weeks at 9am", []time.Time{monday.Add(9 * time.Hour), monday.Add(14*24*time.Hour + 9*time.Hour)}},
+		{
+			"every 2 days at 9am and 5:30pm",
+			[]time.Time{
+				today.Add(9 * time.Hour), today.Add(17*time.Hour + 30*time.Minute),
+				today.Add(57 * time.Hour), today.Add(65*time.Hour + 30*time.Minute),
+			},
+		},
+	}
+
+	parser := NewNaturalParser(WithDefaultLocation(time.UTC))
+
+	for _, test := range tests {
+		sched, err := parser.Parse(test.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		prev := test.expected[0].Truncate(24 * time.Hour).Add(-time.Nanosecond)
+		for _, expected := range test.expected {
+			if actual := sched.Next(prev); !actual.Equal(expected) {
+				t.Errorf("Fail evaluating %s on %s: (expected) %s != %s (actual)", test.spec, prev, expected, actual)
+				break
+			}
+			prev = expected
+		}
+	}
+}
+
+func TestNaturalIntervalDST(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	sched, err := NewNaturalParser(WithDefaultLocation(ny)).Parse("every 2 days at 9:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 一年内经过夏令时的开始和结束，每次仍在当地 9 点执行且相隔 2 个日历日
+	prev := sched.Next(time.Now().AddDate(0, 0, -3))
+	for i := 0; i < 200; i++ {
+		next := sched.Next(prev)
+		local, expected := next.In(ny), prev.In(ny).AddDate(0, 0, 2)
+		if local.Hour() != 9 || local.Minute() != 0 || local.YearDay() != expected.YearDay() {
+			t.Fatalf("Fail evaluating on %s: (expected) %s != %s (actual)", prev.In(ny), expected, local)
+		}
+		prev = next
+	}
+}
+
+func TestNaturalParseError(t *testing.T) {
+	tests := []struct {
+		spec   string
+		reason ParseReason
+		offset int
+	}{
+		{"", ReasonFieldCount, 0},
+		{"every fortnight", ReasonSyntax, 6},
+		{"every day at 25:00", ReasonOutOfRange, 13},
+		{"every day at 9:75", ReasonOutOfRange, 15},
+		{"at 13pm", ReasonOutOfRange, 3},
+		{"every 0 minutes", ReasonStep, 0},
+		{"every 7 minutes on mondays", ReasonStep, 0},
+		{"every 2 days on mondays", ReasonStep, 0},
+		{"every hour every day", ReasonSyntax, 11},
+		{"every 15 minutes at 9am", ReasonSyntax, 20},
+		{"every day between 9am and 5pm", ReasonSyntax, 18},
+		{"between 9am and", ReasonSyntax, 15},
+		{"on the 6th monday", ReasonOutOfRange, 7},
+		{"on the 32nd", ReasonOutOfRange, 7},
+		{"in february on the 30th", ReasonNeverFire, 0},
+		{"每天早上8点吃饭", ReasonSyntax, 16},
+		{"每天25点", ReasonOutOfRange, 6},
+		{"13月1日", ReasonOutOfRange, 0},
+		{"每13个月", ReasonStep, 0},
+		{"到18点", ReasonSyntax, 0},
+		{"每月第六个周五", ReasonOutOfRange, 6},
+		{"每5", ReasonSyntax, 4},
+	}
+
+	for _, test := range tests {
+		_, err := NewNaturalParser().Parse(test.spec)
+
+		var pe *ParseError
+		if !errors.As(err, &pe) || pe.Reason != test.reason || pe.Offset != test.offset || pe.Expr != test.spec {
+			t.Errorf("unexpected error for %q: %v", test.spec, err)
+		}
+	}
+}
@@ -53,3 +53,18 @@ func WithMaxGoroutines(max int) option {
 		b.maxGoroutines = max
 	}
 }
+
+type jobOption func(*job)
+
+// WithMaxRuns allows to limit the number of runs of a job,
+// the job is removed after its last run.
+//
+// Default is 0. 0 means no limit
+func WithMaxRuns(n int) jobOption {
+	return func(j *job) {
+		if n < 0 {
+			n = 0
+		}
+		j.maxRuns = n
+	}
+}
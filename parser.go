@@ -1,10 +1,16 @@
 package beat
 
 import (
+	"errors"
 	"fmt"
+	"hash/fnv"
+	"math/bits"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
 )
 
 type LayoutField uint32
@@ -16,13 +22,72 @@ const (
 	Hour
 	Minute
 	Second
+	Year
+	Millisecond
 )
 
 var DefaultLayout = []LayoutField{Month, Dom, Dow, Hour, Minute, Second}
 
+// 月份名称，不区分大小写
+var monthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+// 星期名称，不区分大小写，星期一到星期天使用1-7表示
+var dowNames = map[string]int{
+	"mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6, "sun": 7,
+}
+
+// crontab 的星期名称，星期天到星期六使用0-6表示
+var crontabDowNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+// Quartz 的星期名称，星期天到星期六使用1-7表示
+var quartzDowNames = map[string]int{
+	"sun": 1, "mon": 2, "tue": 3, "wed": 4, "thu": 5, "fri": 6, "sat": 7,
+}
+
+// 域的取值范围及名称
+type valueRange struct {
+	min, max int
+	hashMax  int // H 的默认最大值，避免散列到部分月份不存在的日期
+	names    map[string]int
+}
+
+// 表达式中星期的数字表示方式，解析后统一转换为 ISO 8601 表示
+type dowNumbering uint8
+
+const (
+	dowISO     dowNumbering = iota // 星期一到星期天使用 1-7 表示
+	dowCrontab                     // 星期天到星期六使用 0-6 表示，7 也表示星期天
+	dowQuartz                      // 星期天到星期六使用 1-7 表示
+)
+
+// “日”和“星期”的组合方式
+type DayMatch uint8
+
+const (
+	DayMatchAnd DayMatch = iota // “日”和“星期”都匹配才认为匹配
+	DayMatchOr                  // “日”和“星期”都有限制时，任意一个匹配即认为匹配，同标准 cron
+)
+
+// 解析时“日”和“星期”域是否以 * 或 ? 开头，同 Vixie cron 以此决定 DayMatchOr 是否生效
+type dayStars uint8
+
+const (
+	domStar    dayStars = 1 << iota // “日”域以 * 或 ? 开头
+	dowStar                         // “星期”域以 * 或 ? 开头
+	starParsed                      // 已在解析时记录，未记录时按“日”和“星期”是否为全部值判断
+)
+
 type Parser struct {
 	layout         []LayoutField
-	defaultLoction *time.Location // 缺省时区，解析时未指定时区则以该参数时区解析
+	defaultLoction *time.Location      // 缺省时区，解析时未指定时区则以该参数时区解析
+	dayMatch       DayMatch            // “日”和“星期”的组合方式
+	dowNumbering   dowNumbering        // 星期的数字表示方式
+	calendars      map[string]Calendar // 可在表达式中通过 CAL= 引用的日历
 }
 
 type SchedTime struct {
@@ -32,8 +97,27 @@ type SchedTime struct {
 	Hour   uint64 // 时
 	Minute uint64 // 分
 	Second uint64 // 秒
+	Year   []int  // 年，按升序排列，为空时表示不限制年份
 
-	location *time.Location
+	Millisecond []int // 毫秒，按升序排列，为空时表示仅在整秒执行
+
+	LastDays       uint64 // 月末倒数的日，第 n 位表示倒数第 n+1 天，即 L-n
+	LastWeekday    bool   // 当月最后一个工作日，即 LW
+	NearestWeekday uint64 // 距离指定日最近的当月工作日，即 nW
+	LastDow        uint64 // 当月最后一个星期几，即 nL
+	NthDow         uint64 // 当月第 k 个星期几，即 n#k，第 k*8+n 位
+
+	DayMatch DayMatch // “日”和“星期”的组合方式
+
+	location     *time.Location
+	layout       []LayoutField // 解析时的 layout，用于生成表达式
+	dowNumbering dowNumbering  // 解析时星期的数字表示方式，用于生成表达式
+	dayStars     dayStars      // 解析时“日”和“星期”域是否以 * 或 ? 开头
+}
+
+// 固定间隔的定时，间隔精确到秒
+type SchedEvery struct {
+	Interval time.Duration // 间隔
 }
 
 var defaultParser = NewParser()
@@ -76,52 +160,234 @@ func (f LayoutField) Bounds() (min, max int) {
 	case Second:
 		min = 0
 		max = 59
+
+	case Year:
+		min = 1970
+		max = 2099
+
+	case Millisecond:
+		min = 0
+		max = 999
 	}
 
 	return
 }
 
-// 解析时间表达式
+// 解析时间表达式，等同于使用空的任务ID调用 ParseWithID
+//
+// 除按 layout 排列的表达式外，还支持以下预定义表达式：
+//
+//	@yearly (或 @annually) 每年 1 月 1 日 00:00:00
+//	@monthly               每月 1 日 00:00:00
+//	@weekly                每周日 00:00:00
+//	@daily (或 @midnight)  每天 00:00:00
+//	@hourly                每小时整点
+//	@every <duration>      每隔固定时间，如 @every 1h30m
+//	@every <duration> from <time> [until <time>]
+//	                       从起点起每隔固定时间，不受时、日等域的边界影响，
+//	                       如 @every 36h from 2026-01-01T00:00:00Z
+//
+// 多个表达式可以使用 | 连接，得到各个定时的并集，如 "* * 1-5 9-17 */15 0 | @daily"；
+// 第一个表达式的 TZ= 及 CAL= 前缀作用于所有表达式，其余表达式可以使用自己的前缀覆盖
 func (p *Parser) Parse(exp string) (Schedule, error) {
-	fields := strings.Fields(exp)
+	return p.ParseWithID(exp, "")
+}
 
-	if len(fields) < len(p.layout) {
-		return nil, fmt.Errorf("%w: invalid number of fields", ErrInvalidExp)
+// 根据任务ID解析时间表达式
+//
+// 表达式中的 H 将根据任务ID散列为固定的值，使大量任务分散执行：
+//
+//	H        在域的范围内取一个值，“日”域的范围为 1-28
+//	H(a-b)   在 a-b 范围内取一个值
+//	H/n      在域的范围内每隔 n 取值，起始值在 0-(n-1) 的偏移内散列
+//	H(a-b)/n 在 a-b 范围内每隔 n 取值
+//
+// 永远不会执行的定时 (如 2 月 30 日) 将解析失败，错误满足 errors.Is(err, ErrNeverFire)
+//
+// 表达式可以使用 CAL=name[:next|:prev] 前缀引用 WithCalendar 注册的日历，
+// 跳过假日或将假日的执行调整到相邻的工作日，参见 SchedCalendar
+func (p *Parser) ParseWithID(exp string, id string) (Schedule, error) {
+	if strings.Contains(exp, "|") {
+		prefix, err := p.unionPrefix(exp)
+		if err != nil {
+			return nil, err
+		}
+
+		return parseUnion(exp, id, func(part string, id string) (Schedule, error) {
+			return p.parse(part, id, prefix)
+		})
 	}
 
-	st := new(SchedTime)
-	st.location = p.defaultLoction
-	offset := 0
+	return p.parse(exp, id, p.defaultPrefix())
+}
 
-	if loc, found := strings.CutPrefix(fields[0], "TZ="); found {
-		if len(fields)-1 < len(p.layout) {
-			return nil, fmt.Errorf("%w: invalid number of fields", ErrInvalidExp)
-		}
+// 表达式的 TZ= 及 CAL= 前缀
+type exprPrefix struct {
+	location *time.Location
+	cal      Calendar
+	adjust   HolidayAdjust
+}
 
-		location, err := time.LoadLocation(loc)
-		if err != nil {
-			return nil, fmt.Errorf("bad location '%s': %v", loc, err)
+// 获取未指定前缀时使用的缺省前缀
+func (p *Parser) defaultPrefix() exprPrefix {
+	return exprPrefix{location: p.defaultLoction, adjust: AdjustSkip}
+}
+
+// 获取以 | 连接的表达式中第一个表达式的前缀，作为各个表达式的缺省前缀，各表达式自身的前缀优先
+func (p *Parser) unionPrefix(exp string) (exprPrefix, error) {
+	prefix := p.defaultPrefix()
+
+	first, _, _ := strings.Cut(exp, "|")
+	fields, offsets := splitFields(first)
+	if _, _, err := p.parsePrefix(&prefix, fields, offsets); err != nil {
+		return prefix, locateError(err, exp, 0, -1, 0)
+	}
+
+	return prefix, nil
+}
+
+// 解析 TZ= 及 CAL= 前缀并更新 prefix，返回其余的域及偏移
+func (p *Parser) parsePrefix(prefix *exprPrefix, fields []string, offsets []int) ([]string, []int, error) {
+	for len(fields) > 0 && isPrefixField(fields[0]) {
+		if loc, found := strings.CutPrefix(fields[0], "TZ="); found {
+			location, err := time.LoadLocation(loc)
+			if err != nil {
+				return nil, nil, newParseError(ReasonLocation, offsets[0]+len("TZ="), "bad location '%s': %v", loc, err)
+			}
+			prefix.location = location
+		} else {
+			cal, adjust, err := p.lookupCalendar(strings.TrimPrefix(fields[0], "CAL="))
+			if err != nil {
+				return nil, nil, shiftError(err, offsets[0]+len("CAL="))
+			}
+			prefix.cal, prefix.adjust = cal, adjust
 		}
 
-		st.location = location
-		offset = 1
+		fields, offsets = fields[1:], offsets[1:]
+	}
+
+	return fields, offsets, nil
+}
+
+// 解析不含 | 的单个表达式，prefix 为表达式未指定前缀时使用的时区和日历
+func (p *Parser) parse(exp string, id string, prefix exprPrefix) (Schedule, error) {
+	fields, offsets := splitFields(exp)
+	fields, offsets, err := p.parsePrefix(&prefix, fields, offsets)
+	if err != nil {
+		return nil, locateError(err, exp, 0, -1, 0)
+	}
+
+	st := new(SchedTime)
+	st.location = prefix.location
+	st.DayMatch = p.dayMatch
+	st.layout = p.layout
+	st.dowNumbering = p.dowNumbering
+
+	sched, err := p.parseSchedule(exp, id, fields, offsets, st)
+	if err != nil || prefix.cal == nil {
+		return sched, err
+	}
+
+	return OnBusinessDays(sched, prefix.cal, prefix.adjust), nil
+}
+
+// 判断域是否以 * 或 ? 开头，如 *、*/2、?
+func isStarField(field string) bool {
+	return strings.HasPrefix(field, "*") || strings.HasPrefix(field, "?")
+}
+
+// 判断域是否为 TZ= 或 CAL= 前缀
+func isPrefixField(field string) bool {
+	return strings.HasPrefix(field, "TZ=") || strings.HasPrefix(field, "CAL=")
+}
+
+// 获取 CAL= 前缀引用的日历及调整方式，前缀形式为 name[:skip|:next|:prev]
+func (p *Parser) lookupCalendar(value string) (Calendar, HolidayAdjust, error) {
+	name, mode, _ := strings.Cut(value, ":")
+
+	cal, found := p.calendars[name]
+	if !found {
+		return nil, 0, newParseError(ReasonCalendar, 0, "unknown calendar: %s", name)
+	}
+
+	switch strings.ToLower(mode) {
+	case "", "skip":
+		return cal, AdjustSkip, nil
+	case "next":
+		return cal, AdjustNext, nil
+	case "prev":
+		return cal, AdjustPrev, nil
 	}
 
-	for i := range p.layout {
-		bits, err := parseField(fields[i+offset], p.layout[i])
+	return nil, 0, newParseError(ReasonCalendar, len(name)+1, "unknown holiday adjustment: %s", mode)
+}
+
+// 解析去掉前缀后的表达式，st 中已设置好时区
+func (p *Parser) parseSchedule(exp string, id string, fields []string, offsets []int, st *SchedTime) (Schedule, error) {
+	if len(fields) > 0 && strings.HasPrefix(fields[0], "@") {
+		sched, err := parseDescriptor(fields, offsets, st)
 		if err != nil {
-			return nil, err
+			return nil, locateError(err, exp, 0, -1, 0)
 		}
+		return sched, nil
+	}
 
-		switch p.layout[i] {
-		case Month:
-			st.Month = bits
+	if len(fields) < len(p.layout) {
+		return nil, locateError(newParseError(ReasonFieldCount, len(exp), "invalid number of fields"), exp, 0, -1, 0)
+	}
 
+	p.applyDefaults(st)
+
+	// 仅 DayMatchOr 需要记录，layout 中不存在的“日”或“星期”视为 *
+	if p.dayMatch == DayMatchOr {
+		st.dayStars = starParsed | domStar | dowStar
+	}
+
+	for i, lf := range p.layout {
+		switch lf {
 		case Dom:
-			st.Dom = bits
+			if st.dayStars != 0 && !isStarField(fields[i]) {
+				st.dayStars &^= domStar
+			}
+			if err := parseDom(fields[i], st, hashOf(id, Dom)); err != nil {
+				return nil, locateError(err, exp, lf, i, offsets[i])
+			}
+			continue
 
 		case Dow:
-			st.Dow = bits
+			if st.dayStars != 0 && !isStarField(fields[i]) {
+				st.dayStars &^= dowStar
+			}
+			if err := parseDow(fields[i], st, p.dowNumbering, hashOf(id, Dow)); err != nil {
+				return nil, locateError(err, exp, lf, i, offsets[i])
+			}
+			continue
+
+		case Year:
+			years, err := parseYear(fields[i], hashOf(id, Year))
+			if err != nil {
+				return nil, locateError(err, exp, lf, i, offsets[i])
+			}
+			st.Year = years
+			continue
+
+		case Millisecond:
+			millis, err := parseMillisecond(fields[i], hashOf(id, Millisecond))
+			if err != nil {
+				return nil, locateError(err, exp, lf, i, offsets[i])
+			}
+			st.Millisecond = millis
+			continue
+		}
+
+		bits, err := parseField(fields[i], lf, hashOf(id, lf))
+		if err != nil {
+			return nil, locateError(err, exp, lf, i, offsets[i])
+		}
+
+		switch lf {
+		case Month:
+			st.Month = bits
 
 		case Hour:
 			st.Hour = bits
@@ -134,38 +400,539 @@ func (p *Parser) Parse(exp string) (Schedule, error) {
 		}
 	}
 
+	if err := st.Validate(); errors.Is(err, ErrNeverFire) {
+		pe := newParseError(ReasonNeverFire, 0, "%s", err)
+		pe.Err = err
+		return nil, locateError(pe, exp, 0, -1, 0)
+	}
+
+	return st, nil
+}
+
+// 按空白分割表达式，同时返回每个域在表达式中的字节偏移
+func splitFields(exp string) ([]string, []int) {
+	fields := make([]string, 0)
+	offsets := make([]int, 0)
+
+	start := -1
+	for i, r := range exp {
+		switch {
+		case unicode.IsSpace(r) && start >= 0:
+			fields = append(fields, exp[start:i])
+			offsets = append(offsets, start)
+			start = -1
+
+		case !unicode.IsSpace(r) && start < 0:
+			start = i
+		}
+	}
+
+	if start >= 0 {
+		fields = append(fields, exp[start:])
+		offsets = append(offsets, start)
+	}
+
+	return fields, offsets
+}
+
+// 为未在 layout 中的域设置缺省值
+//
+// 比 layout 中最小单位更大的域不做限制，更小的域取最小值，
+// 如 layout 中最小单位为分钟时，秒为 0
+func (p *Parser) applyDefaults(st *SchedTime) {
+	// 由大到小排列的域，“日”和“星期”属于同一单位，
+	// 毫秒为空时即表示最小值，因此无需设置
+	units := [][]LayoutField{{Month}, {Dom, Dow}, {Hour}, {Minute}, {Second}, {Millisecond}}
+
+	finest := -1
+	for i, unit := range units {
+		for _, lf := range unit {
+			if slices.Contains(p.layout, lf) {
+				finest = i
+			}
+		}
+	}
+
+	for i, unit := range units {
+		for _, lf := range unit {
+			if slices.Contains(p.layout, lf) {
+				continue
+			}
+
+			bits := lf.all()
+			if i > finest {
+				min, _ := lf.Bounds()
+				bits = 1 << min
+			}
+
+			switch lf {
+			case Month:
+				st.Month = bits
+			case Dom:
+				st.Dom = bits
+			case Dow:
+				st.Dow = lf.all()
+			case Hour:
+				st.Hour = bits
+			case Minute:
+				st.Minute = bits
+			case Second:
+				st.Second = bits
+			}
+		}
+	}
+}
+
+// 获取域的全部有效位
+func (f LayoutField) all() uint64 {
+	min, max := f.Bounds()
+	return (1<<(max+1) - 1) &^ (1<<min - 1)
+}
+
+// 解析预定义表达式，st 中已设置好时区，offsets 为各个域在表达式中的偏移
+func parseDescriptor(fields []string, offsets []int, st *SchedTime) (Schedule, error) {
+	name := strings.ToLower(fields[0])
+
+	if name == "@every" {
+		return parseEvery(fields, offsets)
+	}
+
+	if len(fields) != 1 {
+		return nil, newParseError(ReasonDescriptor, offsets[1], "unexpected fields after %s", fields[0])
+	}
+
+	st.Month = Month.all()
+	st.Dom = Dom.all()
+	st.Dow = Dow.all()
+	st.Hour = 1 << 0
+	st.Minute = 1 << 0
+	st.Second = 1 << 0
+
+	switch name {
+	case "@yearly", "@annually":
+		st.Month = 1 << 1
+		st.Dom = 1 << 1
+
+	case "@monthly":
+		st.Dom = 1 << 1
+
+	case "@weekly":
+		st.Dow = 1 << 7
+
+	case "@daily", "@midnight":
+
+	case "@hourly":
+		st.Hour = Hour.all()
+
+	default:
+		return nil, newParseError(ReasonDescriptor, offsets[0], "unrecognized descriptor: %s", fields[0])
+	}
+
 	return st, nil
 }
 
+// 解析 @every 表达式，支持以下形式：
+//
+//	@every <duration>
+//	@every <duration> from <time> [until <time>]
+//
+// 时间使用 RFC 3339 格式，带有起点时返回 IntervalSchedule
+func parseEvery(fields []string, offsets []int) (Schedule, error) {
+	if len(fields) < 2 {
+		return nil, newParseError(ReasonDescriptor, offsets[0], "@every requires a duration")
+	}
+
+	interval, err := time.ParseDuration(fields[1])
+	if err != nil {
+		return nil, newParseError(ReasonDescriptor, offsets[1], "%s", err)
+	}
+	if interval < time.Second {
+		return nil, newParseError(ReasonDescriptor, offsets[1], "interval must be at least 1s: %s", fields[1])
+	}
+	interval = interval.Truncate(time.Second)
+
+	switch {
+	case len(fields) == 2:
+		return &SchedEvery{Interval: interval}, nil
+
+	case !strings.EqualFold(fields[2], "from"):
+		return nil, newParseError(ReasonDescriptor, offsets[2], "unexpected fields after @every")
+
+	case len(fields) == 3:
+		return nil, newParseError(ReasonDescriptor, offsets[2], "from requires a time")
+
+	case len(fields) != 4 && (len(fields) != 6 || !strings.EqualFold(fields[4], "until")):
+		return nil, newParseError(ReasonDescriptor, offsets[min(4, len(fields)-1)], "unexpected fields after @every")
+	}
+
+	anchor, err := time.Parse(time.RFC3339, fields[3])
+	if err != nil {
+		return nil, newParseError(ReasonDescriptor, offsets[3], "%s", err)
+	}
+
+	is := NewIntervalSchedule(anchor, interval)
+
+	if len(fields) == 6 {
+		end, err := time.Parse(time.RFC3339, fields[5])
+		if err != nil {
+			return nil, newParseError(ReasonDescriptor, offsets[5], "%s", err)
+		}
+		if end.Before(anchor) {
+			return nil, newParseError(ReasonDescriptor, offsets[5], "end is before start: %s", fields[5])
+		}
+		is.End = end
+	}
+
+	return is, nil
+}
+
+// 解析“日”域
+//
+// 除 parseField 支持的符号外，还支持：
+//
+//	?   不指定值，等同于 *
+//	L   当月最后一天
+//	L-n 当月倒数第 n+1 天
+//	LW  当月最后一个工作日
+//	nW  距离 n 日最近的当月工作日
+func parseDom(field string, st *SchedTime, hash uint32) error {
+	if field == "?" {
+		st.Dom = Dom.all()
+		return nil
+	}
+
+	pos := 0
+	for _, exp := range strings.Split(field, ",") {
+		upper := strings.ToUpper(exp)
+
+		switch {
+		case upper == "L":
+			st.LastDays |= 1
+
+		case upper == "LW":
+			st.LastWeekday = true
+
+		case strings.HasPrefix(upper, "L-"):
+			n, err := strconv.Atoi(upper[2:])
+			if err != nil {
+				return newParseError(ReasonSyntax, pos+2, "%s", err)
+			}
+			if n < 0 || n > 30 {
+				return newParseError(ReasonOutOfRange, pos, "out of range: %s", exp)
+			}
+			st.LastDays |= 1 << n
+
+		case strings.HasSuffix(upper, "W"):
+			n, err := strconv.Atoi(upper[:len(upper)-1])
+			if err != nil {
+				return newParseError(ReasonSyntax, pos, "%s", err)
+			}
+			if min, max := Dom.Bounds(); n < min || n > max {
+				return newParseError(ReasonOutOfRange, pos, "out of range: %s", exp)
+			}
+			st.NearestWeekday |= 1 << n
+
+		default:
+			bits, err := parseField(exp, Dom, hash)
+			if err != nil {
+				return shiftError(err, pos)
+			}
+			st.Dom |= bits
+		}
+
+		pos += len(exp) + 1
+	}
+
+	return nil
+}
+
+// 解析“星期”域
+//
+// 除 parseField 支持的符号外，还支持：
+//
+//	?   不指定值，等同于 *
+//	nL  当月最后一个星期 n
+//	n#k 当月第 k 个星期 n，k 为 1-5
+//
+// 表达式中的星期按 numbering 解析，并转换为 ISO 8601 表示
+func parseDow(field string, st *SchedTime, numbering dowNumbering, hash uint32) error {
+	if field == "?" {
+		st.Dow = Dow.all()
+		return nil
+	}
+
+	pos := 0
+	for _, exp := range strings.Split(field, ",") {
+		if err := parseDowExp(exp, st, numbering, hash); err != nil {
+			return shiftError(err, pos)
+		}
+
+		pos += len(exp) + 1
+	}
+
+	return nil
+}
+
+// 解析“星期”域中以逗号分隔的单个表达式
+func parseDowExp(exp string, st *SchedTime, numbering dowNumbering, hash uint32) error {
+	if value, nth, found := strings.Cut(exp, "#"); found {
+		dow, err := parseDowValue(value, exp, numbering)
+		if err != nil {
+			return err
+		}
+
+		k, err := strconv.Atoi(nth)
+		if err != nil {
+			return newParseError(ReasonSyntax, len(value)+1, "%s", err)
+		}
+		if k < 1 || k > 5 {
+			return newParseError(ReasonOutOfRange, len(value)+1, "out of range: %s", exp)
+		}
+
+		st.NthDow |= 1 << (k*8 + dow)
+		return nil
+	}
+
+	// Quartz 中单独的 L 表示一周的最后一天，即星期六
+	if numbering == dowQuartz && strings.ToUpper(exp) == "L" {
+		st.Dow |= 1 << 6
+		return nil
+	}
+
+	if value, found := strings.CutSuffix(strings.ToUpper(exp), "L"); found {
+		dow, err := parseDowValue(value, exp, numbering)
+		if err != nil {
+			return err
+		}
+
+		st.LastDow |= 1 << dow
+		return nil
+	}
+
+	return parseValues(exp, numbering.valueRange(), hash, func(i int) {
+		st.Dow |= 1 << numbering.iso(i)
+	})
+}
+
+// 解析单个星期值，返回 ISO 8601 表示
+func parseDowValue(value string, exp string, numbering dowNumbering) (int, error) {
+	vr := numbering.valueRange()
+
+	dow, err := parseValue(value, vr)
+	if err != nil {
+		return 0, newParseError(ReasonSyntax, 0, "%s", err)
+	}
+	if dow < vr.min || dow > vr.max {
+		return 0, newParseError(ReasonOutOfRange, 0, "out of range: %s", exp)
+	}
+
+	return numbering.iso(dow), nil
+}
+
+// 获取星期的取值范围及名称
+func (n dowNumbering) valueRange() valueRange {
+	switch n {
+	case dowCrontab:
+		return valueRange{min: 0, max: 7, hashMax: 6, names: crontabDowNames}
+
+	case dowQuartz:
+		return valueRange{min: 1, max: 7, hashMax: 7, names: quartzDowNames}
+	}
+
+	return Dow.valueRange()
+}
+
+// 将星期转换为 ISO 8601 表示
+func (n dowNumbering) iso(dow int) int {
+	switch n {
+	case dowCrontab:
+		if dow == 0 {
+			return 7
+		}
+
+	case dowQuartz:
+		if dow == 1 {
+			return 7
+		}
+		return dow - 1
+	}
+
+	return dow
+}
+
+// 获取域的取值范围及名称
+func (f LayoutField) valueRange() valueRange {
+	min, max := f.Bounds()
+
+	hashMax := max
+	if f == Dom {
+		hashMax = 28
+	}
+
+	return valueRange{min: min, max: max, hashMax: hashMax, names: f.names()}
+}
+
+// 获取域支持的名称
+func (f LayoutField) names() map[string]int {
+	switch f {
+	case Month:
+		return monthNames
+
+	case Dow:
+		return dowNames
+	}
+
+	return nil
+}
+
+// 解析域中的值，支持数字或名称
+func parseValue(value string, vr valueRange) (int, error) {
+	if v, ok := vr.names[strings.ToLower(value)]; ok {
+		return v, nil
+	}
+
+	return strconv.Atoi(value)
+}
+
 // 解析域
 //
 // 支持符号：, - * /
-func parseField(field string, lf LayoutField) (uint64, error) {
+//
+// 月份和星期支持使用英文缩写，如 JAN-MAR、MON-FRI
+func parseField(field string, lf LayoutField, hash uint32) (uint64, error) {
+	bits := uint64(0)
+
+	err := parseValues(field, lf.valueRange(), hash, func(i int) {
+		bits |= 1 << i
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return bits, nil
+}
+
+// 解析“年”域，* 表示不限制年份，返回升序排列的年份
+func parseYear(field string, hash uint32) ([]int, error) {
+	if field == "*" {
+		return nil, nil
+	}
+
+	return parseList(field, Year, hash)
+}
+
+// 解析“毫秒”域，返回升序排列的毫秒，仅为 0 时返回空
+func parseMillisecond(field string, hash uint32) ([]int, error) {
+	millis, err := parseList(field, Millisecond, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(millis) == 1 && millis[0] == 0 {
+		return nil, nil
+	}
+
+	return millis, nil
+}
+
+// 解析取值范围超过 64 的域，返回升序排列的有效值
+func parseList(field string, lf LayoutField, hash uint32) ([]int, error) {
+	found := make(map[int]bool)
+	values := make([]int, 0)
+
+	err := parseValues(field, lf.valueRange(), hash, func(i int) {
+		if !found[i] {
+			found[i] = true
+			values = append(values, i)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Ints(values)
+	return values, nil
+}
+
+// 根据任务ID计算域的散列值，用于 H 取值
+func hashOf(id string, lf LayoutField) uint32 {
+	h := fnv.New32a()
+	fmt.Fprintf(h, "%s/%d", id, lf)
+
+	return h.Sum32()
+}
+
+// 解析 H 或 H(a-b)，返回散列的取值范围
+func parseHashRange(exp string, vr valueRange) (int, int, error) {
+	if exp == "H" {
+		return vr.min, vr.hashMax, nil
+	}
+
+	inner, found := strings.CutPrefix(exp, "H(")
+	if found {
+		inner, found = strings.CutSuffix(inner, ")")
+	}
+	if !found {
+		return 0, 0, newParseError(ReasonSyntax, 0, "%s", exp)
+	}
+
+	low, high, found := strings.Cut(inner, "-")
+	if !found {
+		return 0, 0, newParseError(ReasonSyntax, len("H("), "H requires a range: %s", exp)
+	}
+
+	start, err := parseValue(low, vr)
+	if err != nil {
+		return 0, 0, newParseError(ReasonSyntax, len("H("), "%s", err)
+	}
+	end, err := parseValue(high, vr)
+	if err != nil {
+		return 0, 0, newParseError(ReasonSyntax, len("H(")+len(low)+1, "%s", err)
+	}
+
+	if start < vr.min || end > vr.max || start > end {
+		return 0, 0, newParseError(ReasonOutOfRange, 0, "out of range: %s", exp)
+	}
+
+	return start, end, nil
+}
+
+// 解析域中的全部有效值，每个有效值都将调用一次 set，
+// hash 用于确定 H 的取值
+func parseValues(field string, vr valueRange, hash uint32, set func(int)) error {
 	ranges := strings.Split(field, ",")
-	min, max := lf.Bounds()
+	min, max := vr.min, vr.max
 
-	bits := uint64(0)
 	err := error(nil)
+	pos := 0
 	for _, exp := range ranges {
 		start, end, step := 0, 0, 0
 		// 分离范围和步进
 		rangeAndStep := strings.Split(exp, "/")
 		// 分离范围起始和结束
 		lowAndHigh := strings.Split(rangeAndStep[0], "-")
+		// 是否为散列值
+		hashed := strings.HasPrefix(rangeAndStep[0], "H")
 
-		if lowAndHigh[0] == "*" {
+		if hashed {
+			// 先获取散列的范围，确定步长后再计算具体的值
+			start, end, err = parseHashRange(rangeAndStep[0], vr)
+			if err != nil {
+				return shiftError(err, pos)
+			}
+		} else if lowAndHigh[0] == "*" {
 			if len(lowAndHigh) != 1 {
 				// 不允许出现类似 *-2 的表达式
-				return 0, fmt.Errorf("%w: %s", ErrInvalidExp, exp)
+				return newParseError(ReasonSyntax, pos, "%s", exp)
 			}
 			// 若为通配符，则起始和结束分别为最小值和最大值
 			start = min
 			end = max
 		} else {
 			// 首个字符不是通配符，说明表达式中至少标明了起始值，尝试转换为整型
-			start, err = strconv.Atoi(lowAndHigh[0])
+			start, err = parseValue(lowAndHigh[0], vr)
 			if err != nil {
-				return 0, fmt.Errorf("%w: %s", ErrInvalidExp, err)
+				return newParseError(ReasonSyntax, pos, "%s", err)
 			}
 
 			switch len(lowAndHigh) {
@@ -173,13 +940,13 @@ func parseField(field string, lf LayoutField) (uint64, error) {
 				end = start
 
 			case 2: // 长度为2，说明表达式中标明了结束值
-				end, err = strconv.Atoi(lowAndHigh[1])
+				end, err = parseValue(lowAndHigh[1], vr)
 				if err != nil {
-					return 0, fmt.Errorf("%w: %s", ErrInvalidExp, err)
+					return newParseError(ReasonSyntax, pos+len(lowAndHigh[0])+1, "%s", err)
 				}
 
 			default: // 语法错误
-				return 0, fmt.Errorf("%w: too many hyphens: %s", ErrInvalidExp, exp)
+				return newParseError(ReasonSyntax, pos+len(lowAndHigh[0])+1+len(lowAndHigh[1]), "too many hyphens: %s", exp)
 			}
 		}
 
@@ -190,39 +957,55 @@ func parseField(field string, lf LayoutField) (uint64, error) {
 		case 2: // 长度为2，则说明表达式中含有步长
 			step, err = strconv.Atoi(rangeAndStep[1])
 			if err != nil {
-				return 0, fmt.Errorf("%w: %s", ErrInvalidExp, err)
+				return newParseError(ReasonStep, pos+len(rangeAndStep[0])+1, "%s", err)
 			}
 			if step <= 0 {
-				return 0, fmt.Errorf("%w: negative or zero step is not allowed", ErrInvalidExp)
+				return newParseError(ReasonStep, pos+len(rangeAndStep[0])+1, "negative or zero step is not allowed")
 			}
 
 			// 表达式中没有标明结束值，则将结束值设为最大值
-			if len(lowAndHigh) == 1 {
+			if len(lowAndHigh) == 1 && !hashed {
 				end = max
 			}
 		default:
-			return 0, fmt.Errorf("%w: too many slashes: %s", ErrInvalidExp, exp)
+			return newParseError(ReasonSyntax, pos+len(rangeAndStep[0])+1+len(rangeAndStep[1]), "too many slashes: %s", exp)
+		}
+
+		if hashed {
+			if len(rangeAndStep) == 1 {
+				start += int(hash % uint32(end-start+1))
+				end = start
+			} else {
+				// 偏移不能超过范围，否则范围内将没有有效值
+				offset := step
+				if offset > end-start+1 {
+					offset = end - start + 1
+				}
+				start += int(hash % uint32(offset))
+			}
 		}
 
 		// 判断参数是否超出范围
 		if start < min || end > max || start > end {
-			return 0, fmt.Errorf("%w: out of range: %s", ErrInvalidExp, exp)
+			return newParseError(ReasonOutOfRange, pos, "out of range: %s", exp)
 		}
 
-		// 为有效位置1
 		for i := start; i <= end; i += step {
-			bits |= 1 << i
+			set(i)
 		}
+
+		pos += len(exp) + 1
 	}
 
-	return bits, nil
+	return nil
 }
 
 // 获取下一个有效时间
+//
+// 匹配在不受夏令时影响的墙上时间中进行，再转换为实际时间：
+// 因夏令时开始而跳过的墙上时间，将在跳过的时间段结束时执行；
+// 因夏令时结束而重复的墙上时间，仅在第一次出现时执行一次
 func (st *SchedTime) Next(t time.Time) time.Time {
-	// 检查时间域是否匹配，如果匹配，则进行下一个域的匹配。
-	// 如果域不匹配，则增加该域的值。
-
 	// 如果指定了时区，则将给定时间转换为 SchedTime 的时区。
 	// 保存原始时区，以便找到时间后再转换回来。
 	// 请注意，未指定时区的 SchedTime 将被视为本地时区。
@@ -231,94 +1014,476 @@ func (st *SchedTime) Next(t time.Time) time.Time {
 	if loc == time.Local {
 		loc = t.Location()
 	}
-	if st.location != time.Local {
-		t = t.In(st.location)
-	}
+	t = t.In(loc)
 
 	// 匹配机制未匹配到时，将一直增加时间进行匹配，
-	// 此值用于限制匹配失败的上限
-	yearLimit := t.Year() + 2
+	// 此值用于限制匹配失败的上限，极少执行的定时可能数十年才执行一次
+	yearLimit := t.Year() + gregorianCycle
+	if len(st.Year) > 0 {
+		yearLimit = st.Year[len(st.Year)-1]
+	}
+
+	wall := wallClock(t)
+	for {
+		wall = st.nextWall(wall, yearLimit)
+		if wall.IsZero() {
+			return time.Time{}
+		}
+
+		// 重复的墙上时间在第一次出现时已经执行过，需要跳过
+		next := resolveWall(wall, loc)
+		if next.After(t) {
+			return next.In(origLocation)
+		}
+	}
+}
+
+// 获取前一个有效时间，与 Next 使用相同的夏令时规则
+func (st *SchedTime) Prev(t time.Time) time.Time {
+	origLocation := t.Location()
+	loc := st.location
+	if loc == time.Local {
+		loc = t.Location()
+	}
+	t = t.In(loc)
+
+	yearLimit := t.Year() - gregorianCycle
+	if len(st.Year) > 0 {
+		yearLimit = st.Year[0]
+	}
+
+	// 若 t 位于夏令时结束后重复的时间段中，则前一个时区中早于 t 的时间，
+	// 其墙上时间可能晚于 t 的墙上时间，因此从前一个时区的结束处开始查找
+	wall := wallClock(t)
+	if start, _ := t.ZoneBounds(); !start.IsZero() {
+		_, prevOffset := start.Add(-time.Nanosecond).Zone()
+		_, offset := t.Zone()
+
+		end := wallClock(start).Add(time.Duration(prevOffset-offset) * time.Second)
+		if end.After(wall) {
+			wall = end
+		}
+	}
+
+	for {
+		wall = st.prevWall(wall, yearLimit)
+		if wall.IsZero() {
+			return time.Time{}
+		}
+
+		prev := resolveWall(wall, loc)
+		if prev.Before(t) {
+			return prev.In(origLocation)
+		}
+	}
+}
+
+// 获取下一个匹配的墙上时间，墙上时间使用 UTC 表示，
+// 超过 yearLimit 仍未匹配则返回零值时间
+func (st *SchedTime) nextWall(t time.Time, yearLimit int) time.Time {
+	// 由大到小依次在各个域的位图中查找不小于当前值的有效位，
+	// 找到更大的值时将更小的域重置为最小值；找不到时增加上一级的域并重新匹配，
+	// 溢出的值 (如 13 月、32 日、24 时) 在上一级的位图中同样找不到，将继续进位
+
+	// 对齐到下一个最小单位的开始
+	unit := st.unit()
+	t = t.Truncate(unit).Add(unit)
+
+	year, month, day := t.Date()
+	hour, minute, second := t.Clock()
+	nsec := t.Nanosecond()
+
+	// 当月匹配的日，按月缓存
+	var days uint64
+	daysYear, daysMonth := 0, time.Month(0)
+
+	for {
+		// 超过匹配年限则返回零值时间
+		if year > yearLimit {
+			return time.Time{}
+		}
+
+		// 年份不匹配时，直接跳到下一个有效年份的开始
+		if len(st.Year) > 0 {
+			i := sort.SearchInts(st.Year, year)
+			if i == len(st.Year) {
+				return time.Time{}
+			}
+			if st.Year[i] != year {
+				year, month, day, hour, minute, second, nsec = st.Year[i], time.January, 1, 0, 0, 0, 0
+			}
+		}
+
+		m, ok := nextBit(st.Month, int(month))
+		if !ok {
+			year, month, day, hour, minute, second, nsec = year+1, time.January, 1, 0, 0, 0, 0
+			continue
+		}
+		if time.Month(m) != month {
+			month, day, hour, minute, second, nsec = time.Month(m), 1, 0, 0, 0, 0
+		}
+
+		if year != daysYear || month != daysMonth {
+			days, daysYear, daysMonth = st.dayMask(year, month), year, month
+		}
+		d, ok := nextBit(days, day)
+		if !ok {
+			month, day, hour, minute, second, nsec = month+1, 1, 0, 0, 0, 0
+			continue
+		}
+		if d != day {
+			day, hour, minute, second, nsec = d, 0, 0, 0, 0
+		}
+
+		h, ok := nextBit(st.Hour, hour)
+		if !ok {
+			day, hour, minute, second, nsec = day+1, 0, 0, 0, 0
+			continue
+		}
+		if h != hour {
+			hour, minute, second, nsec = h, 0, 0, 0
+		}
+
+		mi, ok := nextBit(st.Minute, minute)
+		if !ok {
+			hour, minute, second, nsec = hour+1, 0, 0, 0
+			continue
+		}
+		if mi != minute {
+			minute, second, nsec = mi, 0, 0
+		}
+
+		s, ok := nextBit(st.Second, second)
+		if !ok {
+			minute, second, nsec = minute+1, 0, 0
+			continue
+		}
+		if s != second {
+			second, nsec = s, 0
+		}
+
+		if len(st.Millisecond) > 0 {
+			i := sort.SearchInts(st.Millisecond, nsec/int(time.Millisecond))
+			if i == len(st.Millisecond) {
+				// 当前秒内没有匹配的毫秒，从下一秒的开始继续匹配
+				second, nsec = second+1, 0
+				continue
+			}
+			nsec = st.Millisecond[i] * int(time.Millisecond)
+		}
+
+		return time.Date(year, month, day, hour, minute, second, nsec, time.UTC)
+	}
+}
+
+// 获取位图中不小于 from 的最小有效位
+func nextBit(b uint64, from int) (int, bool) {
+	if from >= 64 {
+		return 0, false
+	}
 
-	// 对齐到下一秒的开始
-	t = t.Truncate(time.Second).Add(time.Second)
-	added := false
+	b &^= 1<<from - 1
+	if b == 0 {
+		return 0, false
+	}
+
+	return bits.TrailingZeros64(b), true
+}
+
+// 获取指定月份中匹配的日，第 n 位表示 n 日，与 isDayMatch 的结果一致
+func (st *SchedTime) dayMask(year int, month time.Month) uint64 {
+	last := daysIn(year, month)
+	valid := uint64(1)<<(last+1) - 2
+
+	// 1 日的星期，n 日的星期为 (first+n-2)%7+1
+	first := weekday(time.Date(year, month, 1, 0, 0, 0, 0, time.UTC))
+	wday := func(n int) int {
+		return (first+n-2)%7 + 1
+	}
+
+	dom := st.Dom & valid
+	for b := st.LastDays & (1<<last - 1); b != 0; b &= b - 1 {
+		dom |= 1 << (last - bits.TrailingZeros64(b))
+	}
+	if st.LastWeekday {
+		dom |= 1 << nearestDay(last, last, wday(last))
+	}
+	for b := st.NearestWeekday & valid; b != 0; b &= b - 1 {
+		n := bits.TrailingZeros64(b)
+		dom |= 1 << nearestDay(n, last, wday(n))
+	}
+
+	dow := uint64(0)
+	for w := 1; w <= 7; w++ {
+		// 当月第一个星期 w
+		d := (w-first+7)%7 + 1
+
+		if (1<<w)&st.Dow != 0 {
+			for n := d; n <= last; n += 7 {
+				dow |= 1 << n
+			}
+		}
+
+		if (1<<w)&st.LastDow != 0 {
+			dow |= 1 << (d + (last-d)/7*7)
+		}
+
+		for k := 1; k <= 5; k++ {
+			if n := d + (k-1)*7; (1<<(k*8+w))&st.NthDow != 0 && n <= last {
+				dow |= 1 << n
+			}
+		}
+	}
+
+	if st.isDayOr() {
+		return dom | dow
+	}
+
+	return dom & dow
+}
+
+// 获取匹配的最小单位
+func (st *SchedTime) unit() time.Duration {
+	if len(st.Millisecond) > 0 {
+		return time.Millisecond
+	}
+
+	return time.Second
+}
+
+// 获取严格早于 t 的前一个匹配的墙上时间，墙上时间使用 UTC 表示，
+// 早于 yearLimit 仍未匹配则返回零值时间
+func (st *SchedTime) prevWall(t time.Time, yearLimit int) time.Time {
+	// 与 nextWall 相反，域不匹配时，跳到上一个单位的最后时刻
+
+	// 对齐到前一个最小单位的开始
+	unit := st.unit()
+	if truncated := t.Truncate(unit); truncated.Before(t) {
+		t = truncated
+	} else {
+		t = t.Add(-unit)
+	}
 
 LOOP:
-	// 超过匹配年限则返回零值时间
-	if t.Year() > yearLimit {
+	// 早于匹配年限则返回零值时间
+	if t.Year() < yearLimit {
 		return time.Time{}
 	}
 
-	for (1<<t.Month())&st.Month == 0 {
-		if !added {
-			added = true
-			t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+	// 年份不匹配时，直接跳到上一个有效年份的结束
+	if len(st.Year) > 0 {
+		i := sort.SearchInts(st.Year, t.Year())
+		if i == len(st.Year) || st.Year[i] != t.Year() {
+			if i == 0 {
+				return time.Time{}
+			}
+			t = time.Date(st.Year[i-1]+1, time.January, 1, 0, 0, 0, 0, time.UTC).Add(-unit)
 		}
-		t = t.AddDate(0, 1, 0)
+	}
 
-		if t.Month() == time.January {
+	for (1<<t.Month())&st.Month == 0 {
+		t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC).Add(-unit)
+
+		if t.Month() == time.December {
 			goto LOOP
 		}
 	}
 
-	// 不支持 DST（夏令时）
 	for !isDayMatch(st, t) {
-		if !added {
-			added = true
-			t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
-		}
-		t = t.AddDate(0, 0, 1)
+		t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Add(-unit)
 
-		if t.Day() == 1 {
+		if t.Day() == daysIn(t.Year(), t.Month()) {
 			goto LOOP
 		}
 	}
 
 	for (1<<t.Hour())&st.Hour == 0 {
-		if !added {
-			added = true
-			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, loc)
-		}
-		t = t.Add(time.Hour)
+		t = t.Truncate(time.Hour).Add(-unit)
 
-		if t.Hour() == 0 {
+		if t.Hour() == 23 {
 			goto LOOP
 		}
 	}
 
 	for (1<<t.Minute())&st.Minute == 0 {
-		if !added {
-			added = true
-			t = t.Truncate(time.Minute)
-		}
-		t = t.Add(time.Minute)
+		t = t.Truncate(time.Minute).Add(-unit)
 
-		if t.Minute() == 0 {
+		if t.Minute() == 59 {
 			goto LOOP
 		}
 	}
 
 	for (1<<t.Second())&st.Second == 0 {
-		if !added {
-			added = true
-			t = t.Truncate(time.Second)
+		t = t.Truncate(time.Second).Add(-unit)
+
+		if t.Second() == 59 {
+			goto LOOP
 		}
-		t = t.Add(time.Second)
+	}
 
-		if t.Second() == 0 {
+	if len(st.Millisecond) > 0 {
+		ms := t.Nanosecond() / int(time.Millisecond)
+		i := sort.SearchInts(st.Millisecond, ms+1) - 1
+		if i < 0 {
+			// 当前秒内没有匹配的毫秒，从上一秒的结束继续匹配
+			t = t.Truncate(time.Second).Add(-unit)
 			goto LOOP
 		}
+
+		t = t.Add(-time.Duration(ms-st.Millisecond[i]) * time.Millisecond)
+	}
+
+	return t
+}
+
+// 获取给定时间的墙上时间，使用 UTC 表示，以避免计算时受夏令时影响
+func wallClock(t time.Time) time.Time {
+	year, month, day := t.Date()
+	hour, min, sec := t.Clock()
+
+	return time.Date(year, month, day, hour, min, sec, t.Nanosecond(), time.UTC)
+}
+
+// 将墙上时间转换为指定时区的时间
+//
+// 墙上时间不存在时 (夏令时开始，时钟向前调整)，返回时钟调整的时刻；
+// 墙上时间重复时 (夏令时结束，时钟向后调整)，返回第一次出现的时间
+func resolveWall(wall time.Time, loc *time.Location) time.Time {
+	year, month, day := wall.Date()
+	hour, min, sec := wall.Clock()
+	t := time.Date(year, month, day, hour, min, sec, wall.Nanosecond(), loc)
+
+	start, end := t.ZoneBounds()
+
+	if actual := wallClock(t); !actual.Equal(wall) {
+		// 墙上时间位于时钟向前调整的间隙中，time.Date 可能使用调整前或调整后的偏移，
+		// 二者都对应于调整的时刻
+		if actual.After(wall) {
+			return start
+		}
+		return end
+	}
+
+	if start.IsZero() {
+		return t
+	}
+
+	// 若前一个时区的偏移更大，说明时钟在 start 时刻向后调整过，
+	// 该墙上时间可能在前一个时区中已经出现过
+	_, prevOffset := start.Add(-time.Nanosecond).Zone()
+	_, offset := t.Zone()
+	if prevOffset > offset {
+		earlier := t.Add(-time.Duration(prevOffset-offset) * time.Second)
+		if earlier.Before(start) {
+			return earlier
+		}
 	}
 
-	return t.In(origLocation)
+	return t
 }
 
-// 判断“日”是否匹配，匹配规则为：必须“日”和“星期”都匹配，则认为匹配
+// 获取下一个有效时间，即对齐到秒后再加上间隔
+func (se *SchedEvery) Next(t time.Time) time.Time {
+	return t.Truncate(time.Second).Add(se.Interval)
+}
+
+// 获取前一个有效时间，即对齐到秒后再减去间隔
+func (se *SchedEvery) Prev(t time.Time) time.Time {
+	return t.Truncate(time.Second).Add(-se.Interval)
+}
+
+// 判断“日”是否匹配
+//
+// 默认必须“日”和“星期”都匹配，则认为匹配；
+// 若使用 DayMatchOr 且“日”和“星期”都有限制，则任意一个匹配即认为匹配
 func isDayMatch(st *SchedTime, t time.Time) bool {
-	domMatch := ((1 << t.Day()) & st.Dom) != 0
-	dowMatch := ((1 << weekday(t)) & st.Dow) != 0
+	if st.isDayOr() {
+		return isDomMatch(st, t) || isDowMatch(st, t)
+	}
+
+	return isDomMatch(st, t) && isDowMatch(st, t)
+}
+
+// 判断“日”和“星期”是否按任意一个匹配组合
+//
+// 解析得到的定时同 Vixie cron，仅当两个域都不以 * 或 ? 开头时生效，如 */2 仍按都匹配组合；
+// 直接构造的定时则要求两个域都不是全部值
+func (st *SchedTime) isDayOr() bool {
+	if st.DayMatch != DayMatchOr {
+		return false
+	}
+	if st.dayStars&starParsed != 0 {
+		return st.dayStars&(domStar|dowStar) == 0
+	}
+
+	return st.Dom != Dom.all() && st.Dow != Dow.all()
+}
+
+// 判断“日”域是否匹配，包括 L、LW 以及 nW
+func isDomMatch(st *SchedTime, t time.Time) bool {
+	day := t.Day()
+	if (1<<day)&st.Dom != 0 {
+		return true
+	}
+
+	last := daysIn(t.Year(), t.Month())
+	if (1<<(last-day))&st.LastDays != 0 {
+		return true
+	}
+
+	if st.LastWeekday && day == nearestWeekday(t, last, last) {
+		return true
+	}
+
+	for n := 1; n <= last && st.NearestWeekday>>n != 0; n++ {
+		if (1<<n)&st.NearestWeekday != 0 && day == nearestWeekday(t, n, last) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// 判断“星期”域是否匹配，包括 nL 以及 n#k
+func isDowMatch(st *SchedTime, t time.Time) bool {
+	wday := weekday(t)
+	if (1<<wday)&st.Dow != 0 {
+		return true
+	}
+
+	day := t.Day()
+	if (1<<wday)&st.LastDow != 0 && day+7 > daysIn(t.Year(), t.Month()) {
+		return true
+	}
+
+	k := (day-1)/7 + 1
+	return (1<<(k*8+wday))&st.NthDow != 0
+}
+
+// 获取距离当月 n 日最近的工作日，不会跨越月份
+func nearestWeekday(t time.Time, n, last int) int {
+	return nearestDay(n, last, weekday(t.AddDate(0, 0, n-t.Day())))
+}
+
+// 获取距离当月 n 日最近的工作日，wday 为 n 日的星期，last 为当月天数
+func nearestDay(n, last, wday int) int {
+	switch {
+	case wday == 6 && n == 1:
+		return n + 2
+	case wday == 6:
+		return n - 1
+	case wday == 7 && n == last:
+		return n - 2
+	case wday == 7:
+		return n + 1
+	}
+
+	return n
+}
 
-	return domMatch && dowMatch
+// 获取指定月份的天数
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
 }
 
 // 获取 ISO 8601 的星期表示，即星期一到星期天使用1-7表示
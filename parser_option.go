@@ -17,3 +17,32 @@ func WithDefaultLocation(location *time.Location) parserOption {
 		p.defaultLoction = location
 	}
 }
+
+// WithDayMatch allows to specify how day of month and day of week are combined.
+//
+// Default is DayMatchAnd.
+func WithDayMatch(mode DayMatch) parserOption {
+	return func(p *Parser) {
+		p.dayMatch = mode
+	}
+}
+
+// WithCalendar allows to register a calendar referenced by CAL=name in expressions.
+//
+// Use CAL=name, CAL=name:next or CAL=name:prev to skip holidays,
+// or move them to the next or previous business day.
+func WithCalendar(name string, cal Calendar) parserOption {
+	return func(p *Parser) {
+		if p.calendars == nil {
+			p.calendars = make(map[string]Calendar)
+		}
+		p.calendars[name] = cal
+	}
+}
+
+// 指定表达式中星期的数字表示方式
+func withDowNumbering(numbering dowNumbering) parserOption {
+	return func(p *Parser) {
+		p.dowNumbering = numbering
+	}
+}
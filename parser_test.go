@@ -1,9 +1,17 @@
 package beat
 
 import (
+	"errors"
 	"fmt"
+	"math/bits"
+	"math/rand/v2"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
+	_ "time/tzdata"
 )
 
 func TestActivation(t *testing.T) {
@@ -174,3 +182,850 @@ func TestParserTimeZone(t *testing.T) {
 		}
 	}
 }
+
+func TestParseNames(t *testing.T) {
+	tests := []struct {
+		field    string
+		lf       LayoutField
+		expected uint64
+	}{
+		{"JAN", Month, 1 << 1},
+		{"jan-Mar", Month, 1<<1 | 1<<2 | 1<<3},
+		{"1,Jun,DEC", Month, 1<<1 | 1<<6 | 1<<12},
+		{"JAN-DEC/3", Month, 1<<1 | 1<<4 | 1<<7 | 1<<10},
+		{"MON-FRI", Dow, 1<<1 | 1<<2 | 1<<3 | 1<<4 | 1<<5},
+		{"sat,SUN", Dow, 1<<6 | 1<<7},
+		{"mon-3,7", Dow, 1<<1 | 1<<2 | 1<<3 | 1<<7},
+	}
+
+	for _, test := range tests {
+		actual, err := parseField(test.field, test.lf, 0)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		if actual != test.expected {
+			t.Errorf("Fail parsing %s: (expected) %b != %b (actual)",
+				test.field, test.expected, actual)
+		}
+	}
+
+	invalids := []struct {
+		field string
+		lf    LayoutField
+	}{
+		{"MON", Month},
+		{"JAN", Dow},
+		{"JANUARY", Month},
+		{"MON", Hour},
+		{"FRI-MON", Dow},
+		{"JAN/FEB", Month},
+	}
+
+	for _, test := range invalids {
+		if _, err := parseField(test.field, test.lf, 0); !errors.Is(err, ErrInvalidExp) {
+			t.Errorf("expected %s to be invalid, got %v", test.field, err)
+		}
+	}
+}
+
+func TestParseDescriptor(t *testing.T) {
+	tests := []struct {
+		spec     string
+		start    string
+		expected string
+	}{
+		{"@yearly", "2024-11-06T10:20:30+08:00", "2025-01-01T00:00:00+08:00"},
+		{"@annually", "2024-11-06T10:20:30+08:00", "2025-01-01T00:00:00+08:00"},
+		{"@monthly", "2024-11-06T10:20:30+08:00", "2024-12-01T00:00:00+08:00"},
+		{"@weekly", "2024-11-06T10:20:30+08:00", "2024-11-10T00:00:00+08:00"},
+		{"@daily", "2024-11-06T10:20:30+08:00", "2024-11-07T00:00:00+08:00"},
+		{"@midnight", "2024-11-06T10:20:30+08:00", "2024-11-07T00:00:00+08:00"},
+		{"@hourly", "2024-11-06T10:20:30+08:00", "2024-11-06T11:00:00+08:00"},
+		{"TZ=UTC @daily", "2024-11-06T10:20:30+08:00", "2024-11-07T08:00:00+08:00"},
+		{"@every 90m", "2024-11-06T10:20:30.5+08:00", "2024-11-06T11:50:30+08:00"},
+		{"@every 1h30m10s", "2024-11-06T10:20:30+08:00", "2024-11-06T11:50:40+08:00"},
+	}
+
+	parser := NewParser(WithDefaultLocation(time.FixedZone("UTC+8", 8*3600)))
+
+	for _, test := range tests {
+		sched, err := parser.Parse(test.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		start, err := time.Parse(time.RFC3339Nano, test.start)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := parseTime(test.expected)
+		actual := sched.Next(start)
+
+		if !expected.Equal(actual) {
+			t.Errorf("Fail evaluating %s on %s: (expected) %s != %s (actual)",
+				test.spec, test.start, expected, actual)
+		}
+	}
+
+	invalids := []string{"@secondly", "@daily 1", "@every", "@every 1x", "@every 500ms", "@every 1h 2h"}
+
+	for _, spec := range invalids {
+		if _, err := parser.Parse(spec); !errors.Is(err, ErrInvalidExp) {
+			t.Errorf("expected %s to be invalid, got %v", spec, err)
+		}
+	}
+}
+
+func TestDayModifiers(t *testing.T) {
+	tests := []struct {
+		spec     string
+		start    string
+		expected []string
+	}{
+		// Last day of the month.
+		{"* L * 0 0 0", "2024-01-15T00:00:00Z", []string{
+			"2024-01-31T00:00:00Z", "2024-02-29T00:00:00Z", "2024-03-31T00:00:00Z", "2024-04-30T00:00:00Z",
+		}},
+		// Third to last day of the month.
+		{"* L-2 * 0 0 0", "2025-01-30T00:00:00Z", []string{
+			"2025-02-26T00:00:00Z", "2025-03-29T00:00:00Z",
+		}},
+		// Last weekday of the month: 2024-03-31 and 2024-08-31 are weekends.
+		{"* LW * 0 0 0", "2024-03-01T00:00:00Z", []string{
+			"2024-03-29T00:00:00Z", "2024-04-30T00:00:00Z", "2024-05-31T00:00:00Z",
+		}},
+		// Nearest weekday to the 15th: 2024-06-15 is a Saturday, 2024-09-15 is a Sunday.
+		{"6,9 15W * 0 0 0", "2024-01-01T00:00:00Z", []string{
+			"2024-06-14T00:00:00Z", "2024-09-16T00:00:00Z", "2025-06-16T00:00:00Z",
+		}},
+		// Nearest weekday never crosses the month: 2024-06-01 is a Saturday, 2024-03-31 is a Sunday.
+		{"* 1W,31W * 0 0 0", "2024-05-30T00:00:00Z", []string{
+			"2024-05-31T00:00:00Z", "2024-06-03T00:00:00Z", "2024-07-01T00:00:00Z",
+		}},
+		// Last Friday of the month.
+		{"* * 5L 0 0 0", "2024-01-01T00:00:00Z", []string{
+			"2024-01-26T00:00:00Z", "2024-02-23T00:00:00Z", "2024-03-29T00:00:00Z",
+		}},
+		// Second Tuesday of the month, mixed with names.
+		{"* * TUE#2 9 0 0", "2024-01-01T00:00:00Z", []string{
+			"2024-01-09T09:00:00Z", "2024-02-13T09:00:00Z", "2024-03-12T09:00:00Z",
+		}},
+		// First Monday and the last Sunday of the month.
+		{"* * 1#1,SUNL 0 0 0", "2024-01-01T00:00:00Z", []string{
+			"2024-01-28T00:00:00Z", "2024-02-05T00:00:00Z", "2024-02-25T00:00:00Z",
+		}},
+		// Both restricted: the last day of the month when it is a Friday.
+		{"* L 5 0 0 0", "2024-01-01T00:00:00Z", []string{
+			"2024-05-31T00:00:00Z", "2025-01-31T00:00:00Z",
+		}},
+	}
+
+	for _, test := range tests {
+		sched, err := defaultParser.Parse(test.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		next := parseTime(test.start)
+		for _, item := range test.expected {
+			actual := sched.Next(next)
+			expected := parseTime(item)
+			if !actual.Equal(expected) {
+				t.Errorf("Fail evaluating %s on %s: (expected) %s != %s (actual)",
+					test.spec, next, expected, actual)
+				break
+			}
+
+			next = actual
+		}
+	}
+
+	invalids := []string{
+		"* L-31 * * * *", "* 32W * * * *", "* W * * * *", "* LX * * * *",
+		"* * 8L * * *", "* * 1#6 * * *", "* * 1#0 * * *", "* * #1 * * *", "* * L * * *",
+	}
+
+	for _, spec := range invalids {
+		if _, err := defaultParser.Parse(spec); !errors.Is(err, ErrInvalidExp) {
+			t.Errorf("expected %s to be invalid, got %v", spec, err)
+		}
+	}
+}
+
+func TestDayMatchOr(t *testing.T) {
+	tests := []struct {
+		spec     string
+		start    string
+		expected []string
+	}{
+		// Both restricted: the 1st, the 15th or any Sunday.
+		{"* 1,15 7 0 0 0", "2024-06-28T00:00:00Z", []string{
+			"2024-06-30T00:00:00Z", "2024-07-01T00:00:00Z", "2024-07-07T00:00:00Z",
+			"2024-07-14T00:00:00Z", "2024-07-15T00:00:00Z",
+		}},
+		// Modifiers take part in the union.
+		{"* L FRI#1 0 0 0", "2024-01-01T00:00:00Z", []string{
+			"2024-01-05T00:00:00Z", "2024-01-31T00:00:00Z", "2024-02-02T00:00:00Z",
+		}},
+		// Only one restricted: same as AND.
+		{"* * 7 0 0 0", "2024-06-28T00:00:00Z", []string{
+			"2024-06-30T00:00:00Z", "2024-07-07T00:00:00Z",
+		}},
+		{"* 1,15 ? 0 0 0", "2024-06-28T00:00:00Z", []string{
+			"2024-07-01T00:00:00Z", "2024-07-15T00:00:00Z",
+		}},
+		{"* ? MON-FRI 0 0 0", "2024-06-28T00:00:00Z", []string{
+			"2024-07-01T00:00:00Z", "2024-07-02T00:00:00Z",
+		}},
+	}
+
+	parser := NewParser(WithDayMatch(DayMatchOr))
+
+	for _, test := range tests {
+		sched, err := parser.Parse(test.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		next := parseTime(test.start)
+		for _, item := range test.expected {
+			actual := sched.Next(next)
+			expected := parseTime(item)
+			if !actual.Equal(expected) {
+				t.Errorf("Fail evaluating %s on %s: (expected) %s != %s (actual)",
+					test.spec, next, expected, actual)
+				break
+			}
+
+			next = actual
+		}
+	}
+
+	// The default parser keeps the AND semantics, and "?" is the same as "*".
+	sched, err := defaultParser.Parse("* ? 7 0 0 0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual := sched.Next(parseTime("2024-06-28T00:00:00Z")); !actual.Equal(parseTime("2024-06-30T00:00:00Z")) {
+		t.Errorf("unexpected next time %s", actual)
+	}
+
+	for _, spec := range []string{"? * * * * *", "* 1,? * * * *", "* * ?,1 * * *"} {
+		if _, err := defaultParser.Parse(spec); !errors.Is(err, ErrInvalidExp) {
+			t.Errorf("expected %s to be invalid, got %v", spec, err)
+		}
+	}
+}
+
+func TestYearField(t *testing.T) {
+	layout := append(append([]LayoutField{}, DefaultLayout...), Year)
+	parser := NewParser(WithLayout(layout))
+
+	tests := []struct {
+		spec     string
+		start    string
+		expected []string
+	}{
+		{"1 1 * 0 0 0 *", "2026-03-01T00:00:00Z", []string{
+			"2027-01-01T00:00:00Z", "2028-01-01T00:00:00Z",
+		}},
+		{"1 1 * 0 0 0 2026-2030/2", "2026-03-01T00:00:00Z", []string{
+			"2028-01-01T00:00:00Z", "2030-01-01T00:00:00Z", "",
+		}},
+		{"11 1 * 3 0 0 2026", "2026-03-01T00:00:00Z", []string{
+			"2026-11-01T03:00:00Z", "",
+		}},
+		{"2 29 * 0 0 0 2030,2040,2035", "2026-03-01T00:00:00Z", []string{
+			"2040-02-29T00:00:00Z", "",
+		}},
+		{"* * * 0 0 0 2030", "2030-12-30T12:00:00Z", []string{
+			"2030-12-31T00:00:00Z", "",
+		}},
+	}
+
+	for _, test := range tests {
+		sched, err := parser.Parse(test.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		next := parseTime(test.start)
+		for _, item := range test.expected {
+			actual := sched.Next(next)
+			expected := parseTime(item)
+			if !actual.Equal(expected) {
+				t.Errorf("Fail evaluating %s on %s: (expected) %s != %s (actual)",
+					test.spec, next, expected, actual)
+				break
+			}
+
+			next = actual
+		}
+	}
+
+	for _, spec := range []string{"* * * * * * 1969", "* * * * * * 2100", "* * * * * * 2030-2026", "* * * * * * MON"} {
+		if _, err := parser.Parse(spec); !errors.Is(err, ErrInvalidExp) {
+			t.Errorf("expected %s to be invalid, got %v", spec, err)
+		}
+	}
+}
+
+func TestDaylightSavingTime(t *testing.T) {
+	tests := []struct {
+		spec     string
+		start    string
+		expected []string
+	}{
+		// America/New_York springs forward at 2024-03-10 02:00 EST.
+		// The skipped 02:30 runs once, right after the gap.
+		{"TZ=America/New_York * * * 2 30 0", "2024-03-09T03:00:00-05:00", []string{
+			"2024-03-10T03:00:00-04:00", "2024-03-11T02:30:00-04:00",
+		}},
+		{"TZ=America/New_York * * * * 0,30 0", "2024-03-10T01:00:00-05:00", []string{
+			"2024-03-10T01:30:00-05:00", "2024-03-10T03:00:00-04:00", "2024-03-10T03:30:00-04:00",
+		}},
+		// America/New_York falls back at 2024-11-03 02:00 EDT.
+		// The repeated 01:30 runs only on its first occurrence.
+		{"TZ=America/New_York * * * 1 30 0", "2024-11-02T12:00:00-04:00", []string{
+			"2024-11-03T01:30:00-04:00", "2024-11-04T01:30:00-05:00",
+		}},
+		{"TZ=America/New_York * * * 1 30 0", "2024-11-03T01:10:00-05:00", []string{
+			"2024-11-04T01:30:00-05:00",
+		}},
+		{"TZ=America/New_York * * * * 0,30 0", "2024-11-03T00:20:00-04:00", []string{
+			"2024-11-03T00:30:00-04:00", "2024-11-03T01:00:00-04:00", "2024-11-03T01:30:00-04:00",
+			"2024-11-03T02:00:00-05:00", "2024-11-03T02:30:00-05:00",
+		}},
+		{"TZ=America/New_York * * * * * 0", "2024-11-03T01:59:00-04:00", []string{
+			"2024-11-03T02:00:00-05:00",
+		}},
+		// Europe/Berlin springs forward at 2024-03-31 02:00 CET and falls back at 2024-10-27 03:00 CEST.
+		{"TZ=Europe/Berlin * * * 2 30 0", "2024-03-30T12:00:00+01:00", []string{
+			"2024-03-31T03:00:00+02:00", "2024-04-01T02:30:00+02:00",
+		}},
+		{"TZ=Europe/Berlin * * * 2 30 0", "2024-10-26T12:00:00+02:00", []string{
+			"2024-10-27T02:30:00+02:00", "2024-10-28T02:30:00+01:00",
+		}},
+		{"TZ=Europe/Berlin * * * 0 0 0", "2024-10-26T12:00:00+02:00", []string{
+			"2024-10-27T00:00:00+02:00", "2024-10-28T00:00:00+01:00",
+		}},
+	}
+
+	for _, test := range tests {
+		sched, err := defaultParser.Parse(test.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		next := parseTime(test.start)
+		for _, item := range test.expected {
+			actual := sched.Next(next)
+			expected := parseTime(item)
+			if !actual.Equal(expected) {
+				t.Errorf("Fail evaluating %s on %s: (expected) %s != %s (actual)",
+					test.spec, next, expected, actual)
+				break
+			}
+
+			next = actual
+		}
+	}
+}
+
+func TestPrev(t *testing.T) {
+	tests := []struct {
+		spec     string
+		start    string
+		expected []string
+	}{
+		{"* * * * * 0/15", "2012-07-09T15:00:00+08:00", []string{
+			"2012-07-09T14:59:45+08:00", "2012-07-09T14:59:30+08:00",
+		}},
+		{"* */2 3 0 0 0", "2025-01-01T00:00:00+08:00", []string{
+			"2024-12-25T00:00:00+08:00", "2024-12-11T00:00:00+08:00", "2024-11-27T00:00:00+08:00",
+		}},
+		{"* L * 12 0 0", "2024-03-01T00:00:00+08:00", []string{
+			"2024-02-29T12:00:00+08:00", "2024-01-31T12:00:00+08:00", "2023-12-31T12:00:00+08:00",
+		}},
+		{"1 1 * 0 0 0", "2024-01-01T00:00:00.5+08:00", []string{
+			"2024-01-01T00:00:00+08:00", "2023-01-01T00:00:00+08:00", "2022-01-01T00:00:00+08:00",
+		}},
+		{"TZ=America/New_York * * * 2 30 0", "2024-03-11T00:00:00-04:00", []string{
+			"2024-03-10T03:00:00-04:00", "2024-03-09T02:30:00-05:00",
+		}},
+		{"TZ=America/New_York * * * * 0,30 0", "2024-11-03T01:10:00-05:00", []string{
+			"2024-11-03T01:30:00-04:00", "2024-11-03T01:00:00-04:00", "2024-11-03T00:30:00-04:00",
+		}},
+	}
+
+	for _, test := range tests {
+		sched, err := defaultParser.Parse(test.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		prev, err := time.Parse(time.RFC3339Nano, test.start)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, item := range test.expected {
+			actual := sched.(ReversibleSchedule).Prev(prev)
+			expected := parseTime(item)
+			if !actual.Equal(expected) {
+				t.Errorf("Fail evaluating %s on %s: (expected) %s != %s (actual)",
+					test.spec, prev, expected, actual)
+				break
+			}
+
+			prev = actual
+		}
+	}
+
+	// Prev is the inverse of Next: no activation exists between Prev(t) and t.
+	specs := []string{
+		"* * * * */7 0/13",
+		"2,8 L-1,15W MON-FRI 9-17 0 0",
+		"TZ=America/New_York * * * 1-3 0,30 0",
+		"TZ=Europe/Berlin * * * 2 * 0",
+	}
+	start := parseTime("2024-03-09T00:00:00Z")
+
+	for _, spec := range specs {
+		sched, err := defaultParser.Parse(spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		for i := 0; i < 2000; i++ {
+			now := start.Add(time.Duration(i) * 4999 * time.Second)
+			prev := sched.(ReversibleSchedule).Prev(now)
+			if prev.IsZero() || !prev.Before(now) || sched.Next(prev).Before(now) {
+				t.Errorf("Fail evaluating %s on %s: prev %s, next of prev %s",
+					spec, now, prev, sched.Next(prev))
+				break
+			}
+		}
+	}
+}
+
+func TestMillisecondField(t *testing.T) {
+	layout := append(append([]LayoutField{}, DefaultLayout...), Millisecond)
+	parser := NewParser(WithLayout(layout))
+
+	tests := []struct {
+		spec     string
+		start    string
+		expected []string
+	}{
+		{"* * * * * 0-1 */250", "2024-07-09T10:00:01.600Z", []string{
+			"2024-07-09T10:00:01.750Z", "2024-07-09T10:01:00Z", "2024-07-09T10:01:00.250Z",
+		}},
+		{"* * * * * * */100", "2024-07-09T10:00:00.950Z", []string{
+			"2024-07-09T10:00:01Z", "2024-07-09T10:00:01.100Z", "2024-07-09T10:00:01.200Z",
+			"2024-07-09T10:00:01.300Z", "2024-07-09T10:00:01.400Z", "2024-07-09T10:00:01.500Z",
+			"2024-07-09T10:00:01.600Z", "2024-07-09T10:00:01.700Z", "2024-07-09T10:00:01.800Z",
+			"2024-07-09T10:00:01.900Z", "2024-07-09T10:00:02Z",
+		}},
+		{"* * * * * * 100,900", "2024-07-09T10:00:59.950Z", []string{
+			"2024-07-09T10:01:00.100Z", "2024-07-09T10:01:00.900Z", "2024-07-09T10:01:01.100Z",
+		}},
+		{"* * * * * 30 0", "2024-07-09T10:00:30.000Z", []string{
+			"2024-07-09T10:01:30Z",
+		}},
+		{"* * * 23 59 59 999", "2024-12-31T12:00:00Z", []string{
+			"2024-12-31T23:59:59.999Z", "2025-01-01T23:59:59.999Z",
+		}},
+	}
+
+	for _, test := range tests {
+		sched, err := parser.Parse(test.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		next, err := time.Parse(time.RFC3339Nano, test.start)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, item := range test.expected {
+			actual := sched.Next(next)
+			expected, err := time.Parse(time.RFC3339Nano, item)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !actual.Equal(expected) {
+				t.Errorf("Fail evaluating %s on %s: (expected) %s != %s (actual)",
+					test.spec, next, expected, actual)
+				break
+			}
+
+			if prev := sched.(ReversibleSchedule).Prev(actual.Add(time.Millisecond)); !prev.Equal(actual) {
+				t.Errorf("Fail evaluating prev %s on %s: (expected) %s != %s (actual)",
+					test.spec, actual.Add(time.Millisecond), actual, prev)
+			}
+
+			next = actual
+		}
+	}
+
+	for _, spec := range []string{"* * * * * * 1000", "* * * * * * -1", "* * * * * * */0"} {
+		if _, err := parser.Parse(spec); !errors.Is(err, ErrInvalidExp) {
+			t.Errorf("expected %s to be invalid, got %v", spec, err)
+		}
+	}
+}
+
+func TestHashField(t *testing.T) {
+	sched := func(spec, id string) *SchedTime {
+		st, err := defaultParser.ParseWithID(spec, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return st.(*SchedTime)
+	}
+
+	// The same id always yields the same schedule.
+	if a, b := sched("* H * H H H", "job-1"), sched("* H * H H H", "job-1"); !reflect.DeepEqual(a, b) {
+		t.Errorf("expected identical schedules, got %+v and %+v", a, b)
+	}
+
+	minutes := make(map[uint64]bool)
+	for i := 0; i < 200; i++ {
+		id := fmt.Sprintf("job-%d", i)
+
+		st := sched("* H * H H(0-29) H/15", id)
+		if bits.OnesCount64(st.Hour) != 1 || bits.OnesCount64(st.Minute) != 1 {
+			t.Fatalf("expected a single hour and minute for %s, got %+v", id, st)
+		}
+		if st.Dom&^(1<<29-1) != 0 || st.Dom&1 != 0 {
+			t.Errorf("expected day of month within 1-28 for %s, got %b", id, st.Dom)
+		}
+		if st.Minute&^(1<<30-1) != 0 {
+			t.Errorf("expected minute within 0-29 for %s, got %b", id, st.Minute)
+		}
+		if offset := bits.TrailingZeros64(st.Second); bits.OnesCount64(st.Second) != 4 ||
+			offset >= 15 || st.Second != (1<<offset)*(1|1<<15|1<<30|1<<45) {
+			t.Errorf("expected every 15 seconds for %s, got %b", id, st.Second)
+		}
+
+		minutes[st.Minute] = true
+	}
+
+	if len(minutes) < 20 {
+		t.Errorf("expected hashed minutes to spread, got %d distinct values", len(minutes))
+	}
+
+	// Sunday is not doubled by the crontab numbering.
+	parser := NewCrontabParser()
+	for i := 0; i < 50; i++ {
+		st, err := parser.ParseWithID("0 0 * * H", fmt.Sprintf("job-%d", i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dow := st.(*SchedTime).Dow; bits.OnesCount64(dow) != 1 || dow&1 != 0 {
+			t.Errorf("unexpected day of week %b", dow)
+		}
+	}
+
+	for _, spec := range []string{"* * * * H(30-10) *", "* * * * H(0-60) *", "* * * * H(5) *", "* * * * H- *", "* * * * HH *"} {
+		if _, err := defaultParser.ParseWithID(spec, "job"); !errors.Is(err, ErrInvalidExp) {
+			t.Errorf("expected %s to be invalid, got %v", spec, err)
+		}
+	}
+}
+
+func TestParseError(t *testing.T) {
+	yearLayout := append(append([]LayoutField{}, DefaultLayout...), Year, Millisecond)
+
+	tests := []struct {
+		parser ScheduleParser
+		spec   string
+		field  LayoutField
+		index  int
+		offset int
+		reason ParseReason
+	}{
+		{defaultParser, "* * * * 60 0", Minute, 4, 8, ReasonOutOfRange},
+		{defaultParser, "* * * * 1,2,x 0", Minute, 4, 12, ReasonSyntax},
+		{defaultParser, "* * * * 1-x 0", Minute, 4, 10, ReasonSyntax},
+		{defaultParser, "* * * * 1-2-3 0", Minute, 4, 11, ReasonSyntax},
+		{defaultParser, "* * * * */0 0", Minute, 4, 10, ReasonStep},
+		{defaultParser, "* * * * 1/2/3 0", Minute, 4, 11, ReasonSyntax},
+		{defaultParser, "  *  *  *  *  *  61", Second, 5, 17, ReasonOutOfRange},
+		{defaultParser, "TZ=UTC * 1,L-x * * * *", Dom, 1, 13, ReasonSyntax},
+		{defaultParser, "* * 1,3#6 * * *", Dow, 2, 8, ReasonOutOfRange},
+		{defaultParser, "* * * * H(0-60) *", Minute, 4, 8, ReasonOutOfRange},
+		{defaultParser, "* * * * 0,H(5-x) *", Minute, 4, 14, ReasonSyntax},
+		{defaultParser, "* * * *", 0, -1, 7, ReasonFieldCount},
+		{defaultParser, "@every 10ms", 0, -1, 7, ReasonDescriptor},
+		{defaultParser, "@bogus", 0, -1, 0, ReasonDescriptor},
+		{defaultParser, "TZ=Nowhere/City @daily", 0, -1, 3, ReasonLocation},
+		{defaultParser, "2 30 * 0 0 0", 0, -1, 0, ReasonNeverFire},
+		{NewParser(WithLayout(yearLayout)), "* * * * * 0 2100 0", Year, 6, 12, ReasonOutOfRange},
+		{NewCrontabParser(), "* * *", 0, -1, 5, ReasonFieldCount},
+		{NewQuartzParser(), "0 0 12 * * *", Dom, 3, 7, ReasonSyntax},
+		{NewQuartzParser(), "0 0 12 ? * MON#x", Dow, 5, 15, ReasonSyntax},
+	}
+
+	for _, test := range tests {
+		_, err := test.parser.Parse(test.spec)
+		if !errors.Is(err, ErrInvalidExp) {
+			t.Errorf("expected %s to be invalid, got %v", test.spec, err)
+			continue
+		}
+
+		var pe *ParseError
+		if !errors.As(err, &pe) {
+			t.Errorf("expected ParseError for %s, got %T", test.spec, err)
+			continue
+		}
+
+		if pe.Expr != test.spec || pe.Field != test.field || pe.Index != test.index ||
+			pe.Offset != test.offset || pe.Reason != test.reason {
+			t.Errorf("Fail parsing %s: (expected) %d %d %d %d != %d %d %d %d (actual)", test.spec,
+				test.field, test.index, test.offset, test.reason,
+				pe.Field, pe.Index, pe.Offset, pe.Reason)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	yearLayout := append(append([]LayoutField{}, DefaultLayout...), Year)
+	yearParser := NewParser(WithLayout(yearLayout))
+
+	tests := []struct {
+		parser   ScheduleParser
+		spec     string
+		expected error
+	}{
+		{defaultParser, "* * * * * *", nil},
+		{defaultParser, "12 31 * 0 0 0", nil},
+		{defaultParser, "2 L-27 * 0 0 0", nil},
+		{defaultParser, "2 L-28 * 0 0 0", ErrRareFire},
+		{defaultParser, "* * 1#5 0 0 0", nil},
+		{defaultParser, "2 29 * 0 0 0", ErrRareFire},
+		{defaultParser, "2 29 1 0 0 0", ErrRareFire},
+		{defaultParser, "2 L-29 * 0 0 0", ErrNeverFire},
+		{defaultParser, "4,6,9,11 31 * 0 0 0", ErrNeverFire},
+		{NewCrontabParser(), "0 0 30 2 1", nil},
+		{yearParser, "2 29 * 0 0 0 2028", nil},
+		{yearParser, "2 29 * 0 0 0 2026-2030", ErrRareFire},
+		{yearParser, "2 29 * 0 0 0 2029", ErrNeverFire},
+	}
+
+	for _, test := range tests {
+		sched, err := test.parser.Parse(test.spec)
+		if test.expected == ErrNeverFire {
+			if !errors.Is(err, ErrNeverFire) || !errors.Is(err, ErrInvalidExp) {
+				t.Errorf("expected %s to never fire, got %v", test.spec, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		if err := sched.(*SchedTime).Validate(); err != test.expected {
+			t.Errorf("Fail validating %s: (expected) %v != %v (actual)", test.spec, test.expected, err)
+		}
+	}
+
+	// 2 月 29 日且为星期一，相隔 28 年
+	sched, err := defaultParser.Parse("2 29 1 0 0 0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := parseTime("2044-02-29T00:00:00Z")
+	if actual := sched.Next(parseTime("2016-03-01T00:00:00Z")); !actual.Equal(expected) {
+		t.Errorf("Fail evaluating rare schedule: (expected) %s != %s (actual)", expected, actual)
+	}
+	expected = parseTime("2016-02-29T00:00:00Z")
+	if actual := sched.(ReversibleSchedule).Prev(parseTime("2044-02-29T00:00:00Z")); !actual.Equal(expected) {
+		t.Errorf("Fail evaluating rare schedule: (expected) %s != %s (actual)", expected, actual)
+	}
+}
+
+// 逐秒、逐分、逐日增加时间的 nextWall 实现，用于验证按位跳转的结果
+func (st *SchedTime) nextWallReference(t time.Time, yearLimit int) time.Time {
+	// 检查时间域是否匹配，如果匹配，则进行下一个域的匹配。
+	// 如果域不匹配，则增加该域的值。
+
+	// 对齐到下一个最小单位的开始
+	unit := st.unit()
+	t = t.Truncate(unit).Add(unit)
+	added := false
+
+LOOP:
+	// 超过匹配年限则返回零值时间
+	if t.Year() > yearLimit {
+		return time.Time{}
+	}
+
+	// 年份不匹配时，直接跳到下一个有效年份的开始
+	if len(st.Year) > 0 {
+		i := sort.SearchInts(st.Year, t.Year())
+		if i == len(st.Year) {
+			return time.Time{}
+		}
+		if st.Year[i] != t.Year() {
+			added = true
+			t = time.Date(st.Year[i], time.January, 1, 0, 0, 0, 0, time.UTC)
+		}
+	}
+
+	for (1<<t.Month())&st.Month == 0 {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+		}
+		t = t.AddDate(0, 1, 0)
+
+		if t.Month() == time.January {
+			goto LOOP
+		}
+	}
+
+	for !isDayMatch(st, t) {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		}
+		t = t.AddDate(0, 0, 1)
+
+		if t.Day() == 1 {
+			goto LOOP
+		}
+	}
+
+	for (1<<t.Hour())&st.Hour == 0 {
+		if !added {
+			added = true
+			t = t.Truncate(time.Hour)
+		}
+		t = t.Add(time.Hour)
+
+		if t.Hour() == 0 {
+			goto LOOP
+		}
+	}
+
+	for (1<<t.Minute())&st.Minute == 0 {
+		if !added {
+			added = true
+			t = t.Truncate(time.Minute)
+		}
+		t = t.Add(time.Minute)
+
+		if t.Minute() == 0 {
+			goto LOOP
+		}
+	}
+
+	for (1<<t.Second())&st.Second == 0 {
+		if !added {
+			added = true
+			t = t.Truncate(time.Second)
+		}
+		t = t.Add(time.Second)
+
+		if t.Second() == 0 {
+			goto LOOP
+		}
+	}
+
+	if len(st.Millisecond) > 0 {
+		ms := t.Nanosecond() / int(time.Millisecond)
+		i := sort.SearchInts(st.Millisecond, ms)
+		if i == len(st.Millisecond) {
+			// 当前秒内没有匹配的毫秒，从下一秒的开始继续匹配
+			added = true
+			t = t.Truncate(time.Second).Add(time.Second)
+			goto LOOP
+		}
+
+		t = t.Add(time.Duration(st.Millisecond[i]-ms) * time.Millisecond)
+	}
+
+	return t
+}
+
+// nextWall gives the same results as the step-by-step reference implementation.
+func TestNextWallDifferential(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(3, 4))
+	yearLayout := append(append([]LayoutField{}, DefaultLayout...), Year, Millisecond)
+	parsers := []ScheduleParser{
+		NewParser(WithLayout(yearLayout)),
+		NewParser(WithLayout(yearLayout), WithDayMatch(DayMatchOr)),
+	}
+
+	pick := func(choices ...string) string {
+		return choices[rnd.IntN(len(choices))]
+	}
+	randomField := func(lf LayoutField, extra ...string) string {
+		min, max := lf.Bounds()
+		switch rnd.IntN(4) {
+		case 0:
+			return "*"
+		case 1:
+			return fmt.Sprintf("%d/%d", min+rnd.IntN(max-min+1), 1+rnd.IntN(10))
+		}
+
+		values := make([]string, 0)
+		for i := 0; i < 1+rnd.IntN(3); i++ {
+			if len(extra) > 0 && rnd.IntN(3) == 0 {
+				values = append(values, extra[rnd.IntN(len(extra))])
+			} else {
+				values = append(values, strconv.Itoa(min+rnd.IntN(max-min+1)))
+			}
+		}
+		return strings.Join(values, ",")
+	}
+
+	count := 0
+	for count < 2000 {
+		spec := strings.Join([]string{
+			randomField(Month),
+			randomField(Dom, "L", "L-3", "LW", "15W", "1W", "31W"),
+			randomField(Dow, "5L", "1#1", "3#5", "7#2"),
+			randomField(Hour),
+			randomField(Minute),
+			randomField(Second),
+			pick("*", "*", "2020-2030/3", "2025"),
+			pick("0", "0", "*/250", "100,900"),
+		}, " ")
+
+		sched, err := parsers[rnd.IntN(len(parsers))].Parse(spec)
+		if err != nil {
+			// 永远不会执行的随机表达式
+			continue
+		}
+		st := sched.(*SchedTime)
+		count++
+
+		wall := time.Date(2018+rnd.IntN(10), time.Month(1+rnd.IntN(12)), 1+rnd.IntN(28),
+			rnd.IntN(24), rnd.IntN(60), rnd.IntN(60), rnd.IntN(1000)*int(time.Millisecond), time.UTC)
+
+		for i := 0; i < 5; i++ {
+			expected := st.nextWallReference(wall, wall.Year()+8)
+			actual := st.nextWall(wall, wall.Year()+8)
+			if !actual.Equal(expected) {
+				t.Fatalf("Fail evaluating %s on %s: (expected) %s != %s (actual)", spec, wall, expected, actual)
+			}
+			if actual.IsZero() {
+				break
+			}
+			wall = actual
+		}
+	}
+}
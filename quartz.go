@@ -0,0 +1,89 @@
+package beat
+
+import (
+	"slices"
+	"strings"
+)
+
+var (
+	quartzLayout     = []LayoutField{Second, Minute, Hour, Dom, Month, Dow}
+	quartzYearLayout = []LayoutField{Second, Minute, Hour, Dom, Month, Dow, Year}
+)
+
+// 兼容 Quartz 的解析器
+//
+// 支持以下形式的表达式：
+//
+//	[second] [minute] [hour] [dom] [month] [dow]
+//	[second] [minute] [hour] [dom] [month] [dow] [year]
+//
+// 星期使用 1-7 表示星期天到星期六，单独的 L 表示星期六；
+// 与 Quartz 相同，“日”和“星期”中必须有且仅有一个为 ?；
+// 多个表达式可以使用 | 连接，得到各个定时的并集
+type QuartzParser struct {
+	parser     *Parser // 6 个域的解析器
+	yearParser *Parser // 带年的 7 个域的解析器
+}
+
+// 创建 Quartz 解析器，opts 中的 WithLayout 将被忽略
+func NewQuartzParser(opts ...parserOption) *QuartzParser {
+	opts = slices.Clip(append([]parserOption{
+		withDowNumbering(dowQuartz),
+	}, opts...))
+
+	return &QuartzParser{
+		parser:     NewParser(append(opts, WithLayout(quartzLayout))...),
+		yearParser: NewParser(append(opts, WithLayout(quartzYearLayout))...),
+	}
+}
+
+// 解析 Quartz 表达式
+func (p *QuartzParser) Parse(exp string) (Schedule, error) {
+	return p.ParseWithID(exp, "")
+}
+
+// 根据任务ID解析 Quartz 表达式，参见 Parser.ParseWithID
+func (p *QuartzParser) ParseWithID(exp string, id string) (Schedule, error) {
+	if strings.Contains(exp, "|") {
+		prefix, err := p.parser.unionPrefix(exp)
+		if err != nil {
+			return nil, err
+		}
+
+		return parseUnion(exp, id, func(part string, id string) (Schedule, error) {
+			return p.parse(part, id, prefix)
+		})
+	}
+
+	return p.parse(exp, id, p.parser.defaultPrefix())
+}
+
+// 解析不含 | 的单个 Quartz 表达式，prefix 为表达式未指定前缀时使用的时区和日历
+func (p *QuartzParser) parse(exp string, id string, prefix exprPrefix) (Schedule, error) {
+	fields, offsets := splitFields(exp)
+	for len(fields) > 0 && isPrefixField(fields[0]) {
+		fields, offsets = fields[1:], offsets[1:]
+	}
+
+	var parser *Parser
+
+	switch len(fields) {
+	case len(quartzLayout):
+		parser = p.parser
+
+	case len(quartzYearLayout):
+		parser = p.yearParser
+
+	default:
+		return nil, locateError(newParseError(ReasonFieldCount, len(exp), "invalid number of fields"), exp, 0, -1, 0)
+	}
+
+	domIndex := slices.Index(quartzLayout, Dom)
+	dom, dow := fields[domIndex], fields[slices.Index(quartzLayout, Dow)]
+	if (dom == "?") == (dow == "?") {
+		err := newParseError(ReasonSyntax, 0, "exactly one of day of month and day of week must be '?'")
+		return nil, locateError(err, exp, Dom, domIndex, offsets[domIndex])
+	}
+
+	return parser.parse(exp, id, prefix)
+}
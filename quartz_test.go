@@ -0,0 +1,101 @@
+package beat
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestQuartzParser(t *testing.T) {
+	tests := []struct {
+		spec     string
+		start    string
+		expected []string
+	}{
+		{"0 0 12 * * ?", "2024-07-09T13:00:00Z", []string{
+			"2024-07-10T12:00:00Z", "2024-07-11T12:00:00Z",
+		}},
+		{"0 0/5 14,18 * * ?", "2024-07-09T14:52:00Z", []string{
+			"2024-07-09T14:55:00Z", "2024-07-09T18:00:00Z", "2024-07-09T18:05:00Z",
+		}},
+		{"0 10,44 14 ? 3 WED", "2024-01-01T00:00:00Z", []string{
+			"2024-03-06T14:10:00Z", "2024-03-06T14:44:00Z", "2024-03-13T14:10:00Z",
+		}},
+		{"0 15 10 ? * MON-FRI", "2024-07-12T11:00:00Z", []string{
+			"2024-07-15T10:15:00Z",
+		}},
+		// Day of week 1 is Sunday and a single L is Saturday.
+		{"0 0 12 ? * 1", "2024-07-09T00:00:00Z", []string{
+			"2024-07-14T12:00:00Z",
+		}},
+		{"0 0 12 ? * L", "2024-07-09T00:00:00Z", []string{
+			"2024-07-13T12:00:00Z",
+		}},
+		{"0 0 12 ? * 2-6", "2024-07-12T13:00:00Z", []string{
+			"2024-07-15T12:00:00Z",
+		}},
+		{"0 0 12 1/5 * ?", "2024-01-30T00:00:00Z", []string{
+			"2024-01-31T12:00:00Z", "2024-02-01T12:00:00Z", "2024-02-06T12:00:00Z",
+		}},
+		{"0 15 10 L * ?", "2024-01-01T00:00:00Z", []string{
+			"2024-01-31T10:15:00Z", "2024-02-29T10:15:00Z",
+		}},
+		{"0 15 10 L-2 * ?", "2024-01-01T00:00:00Z", []string{
+			"2024-01-29T10:15:00Z", "2024-02-27T10:15:00Z", "2024-03-29T10:15:00Z",
+		}},
+		{"0 15 10 15W * ?", "2024-06-01T00:00:00Z", []string{
+			"2024-06-14T10:15:00Z", "2024-07-15T10:15:00Z",
+		}},
+		// 6L is the last Friday and 6#3 the third Friday.
+		{"0 15 10 ? * 6L", "2024-01-01T00:00:00Z", []string{
+			"2024-01-26T10:15:00Z", "2024-02-23T10:15:00Z", "2024-03-29T10:15:00Z",
+		}},
+		{"0 15 10 ? * 6#3", "2024-01-01T00:00:00Z", []string{
+			"2024-01-19T10:15:00Z", "2024-02-16T10:15:00Z", "2024-03-15T10:15:00Z",
+		}},
+		{"0 15 10 ? * FRI#3", "2024-01-01T00:00:00Z", []string{
+			"2024-01-19T10:15:00Z",
+		}},
+		// Optional year field.
+		{"0 0 0 1 1 ? 2030", "2024-01-01T00:00:00Z", []string{
+			"2030-01-01T00:00:00Z", "",
+		}},
+		{"0 15 10 ? * 6L 2025-2026", "2024-01-01T00:00:00Z", []string{
+			"2025-01-31T10:15:00Z",
+		}},
+	}
+
+	parser := NewQuartzParser(WithDefaultLocation(time.UTC))
+
+	for _, test := range tests {
+		sched, err := parser.Parse(test.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		next := parseTime(test.start)
+		for _, item := range test.expected {
+			actual := sched.Next(next)
+			expected := parseTime(item)
+			if !actual.Equal(expected) {
+				t.Errorf("Fail evaluating %s on %s: (expected) %s != %s (actual)",
+					test.spec, next, expected, actual)
+				break
+			}
+
+			next = actual
+		}
+	}
+
+	invalids := []string{
+		"0 0 12 * * *", "0 0 12 ? * ?", "0 0 12 ? * 0", "0 0 12 ? * 8",
+		"0 12 * * ?", "0 0 12 * * ? 2030 1", "@daily",
+	}
+
+	for _, spec := range invalids {
+		if _, err := parser.Parse(spec); !errors.Is(err, ErrInvalidExp) {
+			t.Errorf("expected %s to be invalid, got %v", spec, err)
+		}
+	}
+}
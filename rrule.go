@@ -0,0 +1,1177 @@
+package beat
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RRULE 的重复频率，值越大周期越长
+type Frequency uint8
+
+const (
+	FreqSecondly Frequency = iota + 1 // 每秒
+	FreqMinutely                      // 每分钟
+	FreqHourly                        // 每小时
+	FreqDaily                         // 每天
+	FreqWeekly                        // 每周
+	FreqMonthly                       // 每月
+	FreqYearly                        // 每年
+)
+
+var frequencyNames = map[string]Frequency{
+	"SECONDLY": FreqSecondly,
+	"MINUTELY": FreqMinutely,
+	"HOURLY":   FreqHourly,
+	"DAILY":    FreqDaily,
+	"WEEKLY":   FreqWeekly,
+	"MONTHLY":  FreqMonthly,
+	"YEARLY":   FreqYearly,
+}
+
+var rruleWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// BYDAY 中的星期，N 为 0 时表示每个该星期几，否则表示第 N 个该星期几，负数表示倒数
+//
+// FREQ=MONTHLY 或指定了 BYMONTH 的 FREQ=YEARLY 中为当月的第 N 个，其余为当年的第 N 个
+type NthWeekday struct {
+	Weekday time.Weekday
+	N       int
+}
+
+// RFC 5545 定义的重复规则，由 RRuleParser 解析得到
+//
+// 未指定的 BYxxx 已按 RFC 5545 根据 DTSTART 补全，如 FREQ=MONTHLY 未指定日时，
+// ByMonthDay 为 DTSTART 的日；各列表均按升序排列
+type SchedRRule struct {
+	Freq     Frequency    // 重复频率
+	Interval int          // 间隔的周期数，至少为 1
+	Count    int          // 最多执行的次数，0 表示不限制
+	Until    time.Time    // 结束时间 (含)，零值表示不限制
+	DTStart  time.Time    // 开始时间，计算时使用其时区
+	Wkst     time.Weekday // 每周的第一天
+
+	ByMonth    []int        // 月，1-12
+	ByWeekNo   []int        // 周数，±1-53
+	ByYearDay  []int        // 一年中的第几天，±1-366
+	ByMonthDay []int        // 一月中的第几天，±1-31
+	ByDay      []NthWeekday // 星期
+	ByHour     []int        // 时
+	ByMinute   []int        // 分
+	BySecond   []int        // 秒
+	BySetPos   []int        // 每个周期内选取的第几个时间，±1-366
+
+	cursor *rruleCursor // COUNT 的计数进度
+}
+
+// COUNT 的计数进度，避免每次计算都从 DTSTART 开始计数
+type rruleCursor struct {
+	mu      sync.Mutex
+	k       int       // 已计数的周期数
+	count   int       // 前 k 个周期内的有效时间数
+	last    time.Time // 第 COUNT 个有效时间，尚未计数到时为零值
+	uniform bool      // 每个周期恰好有一个有效时间，此时无需计数
+}
+
+// RFC 5545 RRULE 解析器
+//
+// 表达式由 DTSTART 和 RRULE 两行组成，也可以使用空格分隔，RRULE: 前缀可以省略，例如：
+//
+//	DTSTART;TZID=America/New_York:19970902T090000
+//	RRULE:FREQ=MONTHLY;BYDAY=2TU;BYHOUR=9
+//
+// 支持 FREQ、INTERVAL、COUNT、UNTIL、WKST、BYMONTH、BYWEEKNO、BYYEARDAY、
+// BYMONTHDAY、BYDAY、BYHOUR、BYMINUTE、BYSECOND 及 BYSETPOS。
+// 不带时区的时间使用缺省时区；缺少 DTSTART 时以解析当天的零点作为开始时间，
+// 此时不能使用大于 1 的 INTERVAL、COUNT 及 BYSETPOS，以免每次解析得到不同的相位或重新计数，
+// FREQ=WEEKLY 需指定 BYDAY，FREQ=MONTHLY、FREQ=YEARLY 需指定 BYMONTHDAY、BYDAY 或 BYYEARDAY，以免按解析的日期补全；
+// 与 RFC 5545 不同，DTSTART 不满足规则时不会作为第一次执行
+type RRuleParser struct {
+	location *time.Location // 缺省时区
+}
+
+// 创建 RRULE 解析器，opts 中仅 WithDefaultLocation 有效
+func NewRRuleParser(opts ...parserOption) *RRuleParser {
+	return &RRuleParser{location: NewParser(opts...).defaultLoction}
+}
+
+// 解析 RRULE 表达式
+func (p *RRuleParser) Parse(exp string) (Schedule, error) {
+	fields, offsets := splitFields(exp)
+
+	var dtstart time.Time
+	rule, ruleOffset := "", -1
+
+	for i, field := range fields {
+		name, value, found := strings.Cut(field, ":")
+		upper := strings.ToUpper(name)
+		isRule := (found && upper == "RRULE") || (!found && strings.Contains(field, "="))
+
+		switch {
+		case found && (upper == "DTSTART" || strings.HasPrefix(upper, "DTSTART;")):
+			t, err := p.parseDTStart(name, value)
+			if err != nil {
+				return nil, locateError(err, exp, 0, -1, offsets[i])
+			}
+			dtstart = t
+
+		case isRule && ruleOffset >= 0:
+			return nil, locateError(newParseError(ReasonSyntax, 0, "multiple RRULE are not supported"), exp, 0, -1, offsets[i])
+
+		case isRule && found:
+			rule, ruleOffset = value, offsets[i]+len(name)+1
+
+		case isRule:
+			rule, ruleOffset = field, offsets[i]
+
+		default:
+			return nil, locateError(newParseError(ReasonSyntax, 0, "unexpected content line '%s'", field), exp, 0, -1, offsets[i])
+		}
+	}
+
+	if ruleOffset < 0 {
+		return nil, locateError(newParseError(ReasonSyntax, len(exp), "missing RRULE"), exp, 0, -1, 0)
+	}
+
+	anchored := !dtstart.IsZero()
+	if !anchored {
+		year, month, day := time.Now().In(p.location).Date()
+		dtstart = time.Date(year, month, day, 0, 0, 0, 0, p.location)
+	}
+
+	r, err := parseRRule(rule, dtstart, anchored)
+	if err != nil {
+		return nil, locateError(err, exp, 0, -1, ruleOffset)
+	}
+
+	if r.Next(r.DTStart.Add(-time.Nanosecond)).IsZero() {
+		pe := newParseError(ReasonNeverFire, 0, "%s", ErrNeverFire)
+		pe.Err = ErrNeverFire
+		return nil, locateError(pe, exp, 0, -1, ruleOffset)
+	}
+
+	return r, nil
+}
+
+// 解析 DTSTART，name 为带参数的属性名，如 DTSTART;TZID=America/New_York
+func (p *RRuleParser) parseDTStart(name string, value string) (time.Time, error) {
+	loc := p.location
+
+	params := strings.Split(name, ";")
+	pos := len(params[0]) + 1
+	for _, param := range params[1:] {
+		key, val, _ := strings.Cut(param, "=")
+		if strings.EqualFold(key, "TZID") {
+			location, err := time.LoadLocation(val)
+			if err != nil {
+				return time.Time{}, newParseError(ReasonLocation, pos+len(key)+1, "bad location '%s': %v", val, err)
+			}
+			loc = location
+		}
+		pos += len(param) + 1
+	}
+
+	t, _, err := parseRRuleTime(value, loc)
+	return t, shiftError(err, len(name)+1)
+}
+
+// 解析 iCalendar 的日期或时间，以 Z 结尾的为 UTC 时间，其余使用 loc 时区
+func parseRRuleTime(value string, loc *time.Location) (time.Time, bool, error) {
+	var t time.Time
+	var err error
+
+	isDate := len(value) == len("20060102")
+	switch {
+	case isDate:
+		t, err = time.ParseInLocation("20060102", value, loc)
+	case strings.HasSuffix(value, "Z"):
+		t, err = time.Parse("20060102T150405Z", value)
+	default:
+		t, err = time.ParseInLocation("20060102T150405", value, loc)
+	}
+	if err != nil {
+		return time.Time{}, false, newParseError(ReasonSyntax, 0, "bad time '%s'", value)
+	}
+
+	return t, isDate, nil
+}
+
+// 解析 RRULE 的规则部分，如 FREQ=DAILY;COUNT=10，anchored 表示是否指定了 DTSTART
+func parseRRule(rule string, dtstart time.Time, anchored bool) (*SchedRRule, error) {
+	r := &SchedRRule{Interval: 1, DTStart: dtstart, Wkst: time.Monday}
+
+	// 各规则在 rule 中的偏移，用于报告规则之间的冲突
+	parts := make(map[string]int)
+
+	pos := 0
+	for _, part := range strings.Split(rule, ";") {
+		if part == "" {
+			pos++
+			continue
+		}
+
+		name, value, found := strings.Cut(part, "=")
+		name = strings.ToUpper(name)
+
+		if _, seen := parts[name]; seen {
+			return nil, newParseError(ReasonSyntax, pos, "duplicate rule part '%s'", name)
+		}
+		if !found || value == "" {
+			return nil, newParseError(ReasonSyntax, pos, "bad rule part '%s'", part)
+		}
+		parts[name] = pos
+
+		if err := r.parsePart(name, value); err != nil {
+			return nil, shiftError(err, pos+len(name)+1)
+		}
+
+		pos += len(part) + 1
+	}
+
+	if err := r.check(parts, anchored); err != nil {
+		return nil, err
+	}
+
+	r.applyDefaults()
+
+	// 没有 BYxxx 时每个周期恰好有一个有效时间，第 COUNT 个即为第 COUNT 个周期的时间
+	r.cursor = &rruleCursor{}
+	if r.Count > 0 && r.Freq <= FreqWeekly && !hasByPart(parts, "") {
+		r.cursor.uniform = true
+		r.cursor.last = r.expand(r.period(r.Count - 1))[0]
+	}
+
+	return r, nil
+}
+
+// 解析单个规则，错误的偏移相对于 value
+func (r *SchedRRule) parsePart(name string, value string) error {
+	var err error
+
+	switch name {
+	case "FREQ":
+		freq, found := frequencyNames[strings.ToUpper(value)]
+		if !found {
+			return newParseError(ReasonSyntax, 0, "unknown frequency '%s'", value)
+		}
+		r.Freq = freq
+
+	case "INTERVAL":
+		r.Interval, err = parseRRuleInt(value, 1, math.MaxInt32)
+
+	case "COUNT":
+		r.Count, err = parseRRuleInt(value, 1, math.MaxInt32)
+
+	case "UNTIL":
+		var isDate bool
+		r.Until, isDate, err = parseRRuleTime(value, r.DTStart.Location())
+		if isDate {
+			// 仅有日期时包含当天
+			r.Until = r.Until.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+
+	case "WKST":
+		wkst, found := rruleWeekdays[strings.ToUpper(value)]
+		if !found {
+			return newParseError(ReasonSyntax, 0, "unknown weekday '%s'", value)
+		}
+		r.Wkst = wkst
+
+	case "BYMONTH":
+		r.ByMonth, err = parseRRuleList(value, 1, 12, false)
+
+	case "BYWEEKNO":
+		r.ByWeekNo, err = parseRRuleList(value, 1, 53, true)
+
+	case "BYYEARDAY":
+		r.ByYearDay, err = parseRRuleList(value, 1, 366, true)
+
+	case "BYMONTHDAY":
+		r.ByMonthDay, err = parseRRuleList(value, 1, 31, true)
+
+	case "BYDAY":
+		r.ByDay, err = parseByDay(value)
+
+	case "BYHOUR":
+		r.ByHour, err = parseRRuleList(value, 0, 23, false)
+
+	case "BYMINUTE":
+		r.ByMinute, err = parseRRuleList(value, 0, 59, false)
+
+	case "BYSECOND":
+		r.BySecond, err = parseRRuleList(value, 0, 59, false)
+
+	case "BYSETPOS":
+		r.BySetPos, err = parseRRuleList(value, 1, 366, true)
+
+	default:
+		return newParseError(ReasonSyntax, -len(name)-1, "unsupported rule part '%s'", name)
+	}
+
+	return err
+}
+
+// 检查规则之间的冲突，parts 为各规则在 RRULE 中的偏移，anchored 表示是否指定了 DTSTART
+func (r *SchedRRule) check(parts map[string]int, anchored bool) error {
+	if r.Freq == 0 {
+		return newParseError(ReasonSyntax, 0, "missing FREQ")
+	}
+
+	if _, found := parts["COUNT"]; found && !r.Until.IsZero() {
+		return newParseError(ReasonSyntax, parts["UNTIL"], "COUNT and UNTIL must not be used together")
+	}
+
+	if len(r.ByWeekNo) > 0 && r.Freq != FreqYearly {
+		return newParseError(ReasonSyntax, parts["BYWEEKNO"], "BYWEEKNO is only valid with FREQ=YEARLY")
+	}
+
+	if len(r.ByYearDay) > 0 && (r.Freq == FreqDaily || r.Freq == FreqWeekly || r.Freq == FreqMonthly) {
+		return newParseError(ReasonSyntax, parts["BYYEARDAY"], "BYYEARDAY is not valid with FREQ=DAILY, WEEKLY or MONTHLY")
+	}
+
+	if len(r.ByMonthDay) > 0 && r.Freq == FreqWeekly {
+		return newParseError(ReasonSyntax, parts["BYMONTHDAY"], "BYMONTHDAY is not valid with FREQ=WEEKLY")
+	}
+
+	nthAllowed := r.Freq == FreqMonthly || (r.Freq == FreqYearly && len(r.ByWeekNo) == 0)
+	for _, wd := range r.ByDay {
+		if wd.N != 0 && !nthAllowed {
+			return newParseError(ReasonSyntax, parts["BYDAY"], "numeric BYDAY is only valid with FREQ=MONTHLY or YEARLY without BYWEEKNO")
+		}
+	}
+
+	if len(r.BySetPos) > 0 && !hasByPart(parts, "BYSETPOS") {
+		return newParseError(ReasonSyntax, parts["BYSETPOS"], "BYSETPOS must be used with another BYxxx rule part")
+	}
+
+	// 相位或计数依赖开始时间的规则必须指定 DTSTART
+	if !anchored {
+		for _, name := range []string{"INTERVAL", "COUNT", "BYSETPOS"} {
+			if pos, found := parts[name]; found && (name != "INTERVAL" || r.Interval > 1) {
+				return newParseError(ReasonSyntax, pos, "%s requires DTSTART", name)
+			}
+		}
+
+		// 缺少日的规则会按 DTSTART 补全，此时结果取决于解析的日期
+		noDay := len(r.ByYearDay) == 0 && len(r.ByMonthDay) == 0 && len(r.ByDay) == 0
+		switch {
+		case r.Freq == FreqWeekly && len(r.ByDay) == 0:
+			return newParseError(ReasonSyntax, parts["FREQ"], "FREQ=WEEKLY requires BYDAY or DTSTART")
+		case r.Freq == FreqMonthly && noDay:
+			return newParseError(ReasonSyntax, parts["FREQ"], "FREQ=MONTHLY requires BYMONTHDAY, BYDAY or DTSTART")
+		case r.Freq == FreqYearly && noDay:
+			return newParseError(ReasonSyntax, parts["FREQ"], "FREQ=YEARLY requires BYMONTHDAY, BYDAY, BYYEARDAY or DTSTART")
+		}
+	}
+
+	return nil
+}
+
+// 判断是否指定了 except 以外的 BYxxx 规则
+func hasByPart(parts map[string]int, except string) bool {
+	for name := range parts {
+		if strings.HasPrefix(name, "BY") && name != except {
+			return true
+		}
+	}
+
+	return false
+}
+
+// 按 RFC 5545 根据 DTSTART 补全未指定的规则
+func (r *SchedRRule) applyDefaults() {
+	start := r.DTStart
+	noDay := len(r.ByYearDay) == 0 && len(r.ByMonthDay) == 0 && len(r.ByDay) == 0
+
+	switch {
+	case r.Freq == FreqYearly && noDay && len(r.ByWeekNo) > 0:
+		r.ByDay = []NthWeekday{{Weekday: start.Weekday()}}
+
+	case r.Freq == FreqYearly && noDay:
+		if len(r.ByMonth) == 0 {
+			r.ByMonth = []int{int(start.Month())}
+		}
+		r.ByMonthDay = []int{start.Day()}
+
+	case r.Freq == FreqMonthly && noDay:
+		r.ByMonthDay = []int{start.Day()}
+
+	case r.Freq == FreqWeekly && len(r.ByDay) == 0:
+		r.ByDay = []NthWeekday{{Weekday: start.Weekday()}}
+	}
+
+	if r.Freq > FreqHourly && len(r.ByHour) == 0 {
+		r.ByHour = []int{start.Hour()}
+	}
+	if r.Freq > FreqMinutely && len(r.ByMinute) == 0 {
+		r.ByMinute = []int{start.Minute()}
+	}
+	if r.Freq > FreqSecondly && len(r.BySecond) == 0 {
+		r.BySecond = []int{start.Second()}
+	}
+}
+
+// 解析规则中的整数
+func parseRRuleInt(value string, min, max int) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, newParseError(ReasonSyntax, 0, "bad number '%s'", value)
+	}
+	if n < min || n > max {
+		return 0, newParseError(ReasonOutOfRange, 0, "%d out of range [%d, %d]", n, min, max)
+	}
+
+	return n, nil
+}
+
+// 解析以逗号分隔的整数列表，signed 为 true 时允许使用负数表示倒数，返回升序排列的列表
+func parseRRuleList(value string, min, max int, signed bool) ([]int, error) {
+	values := make([]int, 0)
+
+	pos := 0
+	for _, item := range strings.Split(value, ",") {
+		n, err := parseRRuleInt(strings.TrimPrefix(item, "-"), min, max)
+		if err != nil {
+			return nil, shiftError(err, pos)
+		}
+		if strings.HasPrefix(item, "-") {
+			if !signed {
+				return nil, newParseError(ReasonOutOfRange, pos, "%s out of range [%d, %d]", item, min, max)
+			}
+			n = -n
+		}
+
+		values = append(values, n)
+		pos += len(item) + 1
+	}
+
+	slices.Sort(values)
+	return slices.Compact(values), nil
+}
+
+// 解析 BYDAY，如 MO,-1FR,2TU
+func parseByDay(value string) ([]NthWeekday, error) {
+	days := make([]NthWeekday, 0)
+
+	pos := 0
+	for _, item := range strings.Split(value, ",") {
+		if len(item) < 2 {
+			return nil, newParseError(ReasonSyntax, pos, "bad weekday '%s'", item)
+		}
+
+		prefix, name := item[:len(item)-2], strings.ToUpper(item[len(item)-2:])
+		wd, found := rruleWeekdays[name]
+		if !found {
+			return nil, newParseError(ReasonSyntax, pos+len(prefix), "unknown weekday '%s'", name)
+		}
+
+		n := 0
+		if prefix != "" {
+			var err error
+			n, err = parseRRuleInt(strings.TrimPrefix(strings.TrimPrefix(prefix, "+"), "-"), 1, 53)
+			if err != nil {
+				return nil, shiftError(err, pos)
+			}
+			if strings.HasPrefix(prefix, "-") {
+				n = -n
+			}
+		}
+
+		days = append(days, NthWeekday{Weekday: wd, N: n})
+		pos += len(item) + 1
+	}
+
+	return days, nil
+}
+
+// 获取下一个有效时间
+func (r *SchedRRule) Next(t time.Time) time.Time {
+	k := max(r.periodIndex(wallClock(t.In(r.DTStart.Location())))-1, 0)
+	count, _ := r.countBefore(k)
+
+	next := time.Time{}
+	r.forward(k, count, max(t.Year(), r.DTStart.Year())+gregorianCycle, func(o time.Time) bool {
+		if o.After(t) {
+			next = o.In(t.Location())
+			return false
+		}
+		return true
+	})
+
+	return next
+}
+
+// 获取前一个有效时间
+func (r *SchedRRule) Prev(t time.Time) time.Time {
+	end := t
+	if !r.Until.IsZero() && end.After(r.Until) {
+		end = r.Until.Add(time.Nanosecond)
+	}
+
+	k := r.periodIndex(wallClock(end.In(r.DTStart.Location()))) + 1
+
+	// 已超过 COUNT 时，最后一次即为第 COUNT 个有效时间
+	if count, last := r.countBefore(k); r.Count > 0 && count >= r.Count && end.After(last) {
+		return last.In(t.Location())
+	}
+
+	prev := time.Time{}
+	r.backward(k, func(o time.Time) bool {
+		if !o.Before(end) {
+			return true
+		}
+		prev = o.In(t.Location())
+		return false
+	})
+
+	return prev
+}
+
+// 获取前 k 个周期内的有效时间数及第 COUNT 个有效时间，未指定 COUNT 时返回 0
+//
+// 计数从上次的进度继续，达到 COUNT 后不再计数，此时返回的数量不小于 COUNT
+func (r *SchedRRule) countBefore(k int) (int, time.Time) {
+	if r.Count == 0 {
+		return 0, time.Time{}
+	}
+
+	c := r.cursor
+	if c == nil {
+		// 直接构造的 SchedRRule 没有计数进度
+		c = &rruleCursor{}
+	}
+	if c.uniform {
+		return min(max(k, 0), r.Count), c.last
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.k > k {
+		c.k, c.count = 0, 0
+	}
+
+	for c.k < k && c.count < r.Count {
+		p := r.period(c.k)
+		if next, skip := r.skipForward(p); skip {
+			c.k = min(next, k)
+			continue
+		}
+
+		for _, o := range r.expand(p) {
+			if o.Before(r.DTStart) {
+				continue
+			}
+			if c.count++; c.count == r.Count {
+				c.last = o
+			}
+		}
+		c.k++
+	}
+
+	return c.count, c.last
+}
+
+// 从第 k 个周期开始按时间顺序遍历有效时间，直至 fn 返回 false 或超过 yearLimit 年，
+// count 为前 k 个周期内的有效时间数
+func (r *SchedRRule) forward(k int, count int, yearLimit int, fn func(time.Time) bool) {
+	for {
+		p := r.period(k)
+		if p.Year() > yearLimit {
+			return
+		}
+
+		if next, skip := r.skipForward(p); skip {
+			k = next
+			continue
+		}
+
+		for _, o := range r.expand(p) {
+			if o.Before(r.DTStart) {
+				continue
+			}
+			if !r.Until.IsZero() && o.After(r.Until) {
+				return
+			}
+
+			count++
+			if r.Count > 0 && count > r.Count {
+				return
+			}
+
+			if !fn(o) {
+				return
+			}
+		}
+
+		k++
+	}
+}
+
+// 从第 k 个周期开始按时间倒序遍历有效时间，直至 fn 返回 false 或早于 DTSTART，
+// 不检查 COUNT 及 UNTIL
+func (r *SchedRRule) backward(k int, fn func(time.Time) bool) {
+	for k >= 0 {
+		p := r.period(k)
+
+		if prev, skip := r.skipBackward(p); skip {
+			k = prev
+			continue
+		}
+
+		occurrences := r.expand(p)
+		for i := len(occurrences) - 1; i >= 0; i-- {
+			if occurrences[i].Before(r.DTStart) {
+				return
+			}
+			if !fn(occurrences[i]) {
+				return
+			}
+		}
+
+		k--
+	}
+}
+
+// 获取第 k 个周期的开始时间，使用 UTC 表示的墙上时间
+func (r *SchedRRule) period(k int) time.Time {
+	start := wallClock(r.DTStart)
+	year, month, day := start.Date()
+	hour, min, sec := start.Clock()
+	n := k * r.Interval
+
+	switch r.Freq {
+	case FreqYearly:
+		return time.Date(year+n, 1, 1, 0, 0, 0, 0, time.UTC)
+	case FreqMonthly:
+		return time.Date(year, month+time.Month(n), 1, 0, 0, 0, 0, time.UTC)
+	case FreqWeekly:
+		offset := (int(start.Weekday()) - int(r.Wkst) + 7) % 7
+		return time.Date(year, month, day-offset+7*n, 0, 0, 0, 0, time.UTC)
+	case FreqDaily:
+		return time.Date(year, month, day+n, 0, 0, 0, 0, time.UTC)
+	case FreqHourly:
+		return time.Date(year, month, day, hour+n, 0, 0, 0, time.UTC)
+	case FreqMinutely:
+		return time.Date(year, month, day, hour, min+n, 0, 0, time.UTC)
+	default:
+		return time.Date(year, month, day, hour, min, sec+n, 0, time.UTC)
+	}
+}
+
+// 获取墙上时间 wall 所在周期的序号，早于第一个周期时为负数
+func (r *SchedRRule) periodIndex(wall time.Time) int {
+	start := r.period(0)
+
+	n := 0
+	switch r.Freq {
+	case FreqYearly:
+		n = wall.Year() - start.Year()
+	case FreqMonthly:
+		n = (wall.Year()-start.Year())*12 + int(wall.Month()-start.Month())
+	default:
+		n = r.units(wall)
+	}
+
+	return floorDiv(n, r.Interval)
+}
+
+// 获取墙上时间 wall 距第一个周期的时间单位数，仅用于固定长度的频率，周按 7 天计算
+func (r *SchedRRule) units(wall time.Time) int {
+	seconds := 1
+	switch r.Freq {
+	case FreqWeekly:
+		seconds = 7 * 24 * 3600
+	case FreqDaily:
+		seconds = 24 * 3600
+	case FreqHourly:
+		seconds = 3600
+	case FreqMinutely:
+		seconds = 60
+	}
+
+	return floorDiv(int(wall.Unix()-r.period(0).Unix()), seconds)
+}
+
+// 频率小于一天时，若周期所在的日、时或分不满足规则，返回之后第一个可能满足的周期
+func (r *SchedRRule) skipForward(p time.Time) (int, bool) {
+	_, end, skip := r.skipRange(p)
+	if !skip {
+		return 0, false
+	}
+
+	return -floorDiv(-r.units(end), r.Interval), true
+}
+
+// 频率小于一天时，若周期所在的日、时或分不满足规则，返回之前第一个可能满足的周期
+func (r *SchedRRule) skipBackward(p time.Time) (int, bool) {
+	start, _, skip := r.skipRange(p)
+	if !skip {
+		return 0, false
+	}
+
+	return floorDiv(r.units(start)-1, r.Interval), true
+}
+
+// 获取周期 p 所在的不满足规则的日、时或分的时间范围 [start, end)
+func (r *SchedRRule) skipRange(p time.Time) (time.Time, time.Time, bool) {
+	if r.Freq > FreqHourly {
+		return time.Time{}, time.Time{}, false
+	}
+
+	var start time.Time
+	var length time.Duration
+
+	switch {
+	case !r.dayMatches(p):
+		start, length = p.Truncate(24*time.Hour), 24*time.Hour
+	case r.Freq < FreqHourly && !containsOrEmpty(r.ByHour, p.Hour()):
+		start, length = p.Truncate(time.Hour), time.Hour
+	case r.Freq < FreqMinutely && !containsOrEmpty(r.ByMinute, p.Minute()):
+		start, length = p.Truncate(time.Minute), time.Minute
+	default:
+		return time.Time{}, time.Time{}, false
+	}
+
+	return start, start.Add(length), true
+}
+
+// 获取周期 p 内的全部有效时间，已应用 BYSETPOS，按时间顺序排列
+func (r *SchedRRule) expand(p time.Time) []time.Time {
+	days := r.days(p)
+	hours := r.clockValues(r.ByHour, FreqHourly, p.Hour())
+	minutes := r.clockValues(r.ByMinute, FreqMinutely, p.Minute())
+	seconds := r.clockValues(r.BySecond, FreqSecondly, p.Second())
+
+	walls := make([]time.Time, 0, len(days)*len(hours)*len(minutes)*len(seconds))
+	for _, d := range days {
+		year, month, day := d.Date()
+		for _, hour := range hours {
+			for _, min := range minutes {
+				for _, sec := range seconds {
+					walls = append(walls, time.Date(year, month, day, hour, min, sec, 0, time.UTC))
+				}
+			}
+		}
+	}
+
+	if len(r.BySetPos) > 0 {
+		walls = r.setPos(walls)
+	}
+
+	loc := r.DTStart.Location()
+	occurrences := make([]time.Time, 0, len(walls))
+	for _, wall := range walls {
+		t := resolveWall(wall, loc)
+		if n := len(occurrences); n == 0 || !occurrences[n-1].Equal(t) {
+			occurrences = append(occurrences, t)
+		}
+	}
+
+	return occurrences
+}
+
+// 获取周期 p 内满足规则的日期
+func (r *SchedRRule) days(p time.Time) []time.Time {
+	first, n := p, 1
+
+	switch r.Freq {
+	case FreqYearly:
+		n = daysInYear(p.Year())
+	case FreqMonthly:
+		n = daysIn(p.Year(), p.Month())
+	case FreqWeekly:
+		n = 7
+	default:
+		year, month, day := p.Date()
+		first = time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	days := make([]time.Time, 0)
+	for i := range n {
+		if d := first.AddDate(0, 0, i); r.dayMatches(d) {
+			days = append(days, d)
+		}
+	}
+
+	return days
+}
+
+// 获取时、分、秒的取值，频率比 freq 更长时为规则中的取值，否则为周期 p 中的值
+func (r *SchedRRule) clockValues(values []int, freq Frequency, current int) []int {
+	if r.Freq > freq {
+		return values
+	}
+
+	if containsOrEmpty(values, current) {
+		return []int{current}
+	}
+
+	return nil
+}
+
+// 判断日期是否满足 BYMONTH、BYWEEKNO、BYYEARDAY、BYMONTHDAY 及 BYDAY
+func (r *SchedRRule) dayMatches(d time.Time) bool {
+	year, month, day := d.Date()
+
+	if !containsOrEmpty(r.ByMonth, int(month)) {
+		return false
+	}
+
+	if len(r.ByWeekNo) > 0 {
+		week, weeks := r.weekNo(d)
+		if !slices.Contains(r.ByWeekNo, week) && !slices.Contains(r.ByWeekNo, week-weeks-1) {
+			return false
+		}
+	}
+
+	if len(r.ByYearDay) > 0 {
+		yday := d.YearDay()
+		if !slices.Contains(r.ByYearDay, yday) && !slices.Contains(r.ByYearDay, yday-daysInYear(year)-1) {
+			return false
+		}
+	}
+
+	if len(r.ByMonthDay) > 0 {
+		if !slices.Contains(r.ByMonthDay, day) && !slices.Contains(r.ByMonthDay, day-daysIn(year, month)-1) {
+			return false
+		}
+	}
+
+	return len(r.ByDay) == 0 || r.byDayMatches(d)
+}
+
+// 判断日期是否满足 BYDAY
+func (r *SchedRRule) byDayMatches(d time.Time) bool {
+	year, month, day := d.Date()
+
+	for _, wd := range r.ByDay {
+		if wd.Weekday != d.Weekday() {
+			continue
+		}
+		if wd.N == 0 {
+			return true
+		}
+
+		index, last := d.YearDay(), daysInYear(year)
+		if r.Freq == FreqMonthly || len(r.ByMonth) > 0 {
+			index, last = day, daysIn(year, month)
+		}
+
+		if (wd.N > 0 && (index-1)/7+1 == wd.N) || (wd.N < 0 && (last-index)/7+1 == -wd.N) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// 获取日期所在的周数及该周所属年份的总周数，
+// 每周从 Wkst 开始，第 1 周为当年至少包含 4 天的第一周
+func (r *SchedRRule) weekNo(d time.Time) (int, int) {
+	year := d.Year()
+	start := r.firstWeek(year)
+
+	if d.Before(start) {
+		year--
+		start = r.firstWeek(year)
+	} else if next := r.firstWeek(year + 1); !d.Before(next) {
+		year++
+		start = next
+	}
+
+	weeks := int(r.firstWeek(year+1).Sub(start).Hours()) / (7 * 24)
+	return int(d.Sub(start).Hours())/(7*24) + 1, weeks
+}
+
+// 获取指定年份第 1 周的开始日期
+func (r *SchedRRule) firstWeek(year int) time.Time {
+	jan1 := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	offset := (int(jan1.Weekday()) - int(r.Wkst) + 7) % 7
+	if offset <= 3 {
+		return jan1.AddDate(0, 0, -offset)
+	}
+
+	return jan1.AddDate(0, 0, 7-offset)
+}
+
+// 按 BYSETPOS 选取周期内的时间
+func (r *SchedRRule) setPos(walls []time.Time) []time.Time {
+	selected := make([]time.Time, 0, len(r.BySetPos))
+
+	for _, pos := range r.BySetPos {
+		i := pos - 1
+		if pos < 0 {
+			i = len(walls) + pos
+		}
+		if i >= 0 && i < len(walls) {
+			selected = append(selected, walls[i])
+		}
+	}
+
+	slices.SortFunc(selected, time.Time.Compare)
+	return slices.CompactFunc(selected, time.Time.Equal)
+}
+
+// 判断列表为空或包含 v
+func containsOrEmpty(values []int, v int) bool {
+	return len(values) == 0 || slices.Contains(values, v)
+}
+
+// 获取一年的天数
+func daysInYear(year int) int {
+	return time.Date(year, 12, 31, 0, 0, 0, 0, time.UTC).YearDay()
+}
+
+// 向下取整的整数除法
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+
+	return q
+}
+
+// 各频率的时间单位，依次为英文、中文及间隔大于 1 时的中文
+var rruleUnits = map[Frequency][3]string{
+	FreqSecondly: {"second", "秒", "秒"},
+	FreqMinutely: {"minute", "分钟", "分钟"},
+	FreqHourly:   {"hour", "小时", "小时"},
+	FreqDaily:    {"day", "天", "天"},
+	FreqWeekly:   {"week", "周", "周"},
+	FreqMonthly:  {"month", "月", "个月"},
+	FreqYearly:   {"year", "年", "年"},
+}
+
+func (r *SchedRRule) describe(lang Language) string {
+	unit := rruleUnits[r.Freq]
+	start := r.DTStart.Format(time.RFC3339)
+
+	if lang == Chinese {
+		parts := []string{"自 " + start + " 起"}
+		if r.Interval > 1 {
+			parts = append(parts, fmt.Sprintf("每%d%s", r.Interval, unit[2]))
+		} else {
+			parts = append(parts, "每"+unit[1])
+		}
+		parts = append(parts, r.describeRules(lang)...)
+		if !r.Until.IsZero() {
+			parts = append(parts, "至 "+r.Until.Format(time.RFC3339)+" 止")
+		}
+		if r.Count > 0 {
+			parts = append(parts, fmt.Sprintf("共 %d 次", r.Count))
+		}
+		return strings.Join(parts, "，")
+	}
+
+	parts := []string{"Every " + unit[0]}
+	if r.Interval > 1 {
+		parts[0] = fmt.Sprintf("Every %d %ss", r.Interval, unit[0])
+	}
+	parts = append(parts, r.describeRules(lang)...)
+	parts = append(parts, "starting "+start)
+	if !r.Until.IsZero() {
+		parts = append(parts, "until "+r.Until.Format(time.RFC3339))
+	}
+	if r.Count > 0 {
+		parts = append(parts, fmt.Sprintf("%d times", r.Count))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// 生成各 BYxxx 规则的描述
+func (r *SchedRRule) describeRules(lang Language) []string {
+	zh := lang == Chinese
+	parts := make([]string, 0)
+
+	// 列表中各项的描述，负数表示倒数并排在正数之后
+	list := func(values []int, en func(int) string, chinese func(int) string) []string {
+		items := make([]string, 0, len(values))
+		ordered := slices.Concat(
+			slices.DeleteFunc(slices.Clone(values), func(v int) bool { return v < 0 }),
+			slices.DeleteFunc(slices.Clone(values), func(v int) bool { return v > 0 }),
+		)
+		for _, v := range ordered {
+			if zh {
+				items = append(items, chinese(v))
+			} else {
+				items = append(items, en(v))
+			}
+		}
+		return items
+	}
+	join := joinEnglish
+	if zh {
+		join = joinChinese
+	}
+
+	if len(r.ByMonth) > 0 {
+		months := list(r.ByMonth, func(v int) string { return time.Month(v).String() }, func(v int) string { return chineseMonths[v] })
+		if zh {
+			parts = append(parts, join(months))
+		} else {
+			parts = append(parts, "in "+join(months))
+		}
+	}
+
+	if len(r.ByWeekNo) > 0 {
+		weeks := list(r.ByWeekNo, func(v int) string { return rruleNth(v) + " week" }, func(v int) string { return chineseNth(v, "周") })
+		if zh {
+			parts = append(parts, join(weeks))
+		} else {
+			parts = append(parts, "in the "+join(weeks)+" of the year")
+		}
+	}
+
+	if len(r.ByYearDay) > 0 {
+		days := list(r.ByYearDay, func(v int) string { return rruleNth(v) + " day" }, func(v int) string { return chineseNth(v, "天") })
+		if zh {
+			parts = append(parts, "每年的"+join(days))
+		} else {
+			parts = append(parts, "on the "+join(days)+" of the year")
+		}
+	}
+
+	if len(r.ByMonthDay) > 0 {
+		days := list(r.ByMonthDay, func(v int) string { return rruleNth(v) + " day" }, func(v int) string { return chineseNth(v, "天") })
+		if zh {
+			parts = append(parts, "每月的"+join(days))
+		} else {
+			parts = append(parts, "on the "+join(days)+" of the month")
+		}
+	}
+
+	if len(r.ByDay) > 0 {
+		days := make([]string, 0, len(r.ByDay))
+		for _, wd := range r.ByDay {
+			switch {
+			case zh && wd.N == 0:
+				days = append(days, chineseWeekdays[weekday2ISO(wd.Weekday)])
+			case zh:
+				days = append(days, chineseNth(wd.N, "个"+chineseWeekdays[weekday2ISO(wd.Weekday)]))
+			case wd.N == 0:
+				days = append(days, wd.Weekday.String())
+			default:
+				days = append(days, "the "+rruleNth(wd.N)+" "+wd.Weekday.String())
+			}
+		}
+		if zh {
+			parts = append(parts, join(days))
+		} else {
+			parts = append(parts, "on "+join(days))
+		}
+	}
+
+	if clock := r.describeClock(lang); clock != "" {
+		parts = append(parts, clock)
+	}
+
+	if len(r.BySetPos) > 0 {
+		unit := rruleUnits[r.Freq]
+		positions := list(r.BySetPos, func(v int) string { return rruleNth(v) }, func(v int) string { return chineseNth(v, "个") })
+		if zh {
+			parts = append(parts, "取每"+unit[1]+"中的"+join(positions))
+		} else {
+			parts = append(parts, "using the "+join(positions)+" occurrence in each "+unit[0])
+		}
+	}
+
+	return parts
+}
+
+// 生成 BYHOUR、BYMINUTE 及 BYSECOND 的描述
+func (r *SchedRRule) describeClock(lang Language) string {
+	if r.Freq > FreqHourly && len(r.ByHour) == 1 && len(r.ByMinute) == 1 && len(r.BySecond) == 1 {
+		clock := fmt.Sprintf("%02d:%02d:%02d", r.ByHour[0], r.ByMinute[0], r.BySecond[0])
+		if lang == Chinese {
+			return clock
+		}
+		return "at " + clock
+	}
+
+	hours := ""
+	if len(r.ByHour) > 0 {
+		hours = describeUnit(r.ByHour, Hour.valueRange(), "hour", "点", lang)
+	}
+
+	if r.Freq > FreqMinutely && len(r.ByMinute) == 1 && len(r.BySecond) == 1 {
+		if lang == Chinese {
+			clock := fmt.Sprintf("%02d分%02d秒", r.ByMinute[0], r.BySecond[0])
+			if hours != "" {
+				return hours + "的" + clock
+			}
+			return clock
+		}
+
+		clock := fmt.Sprintf("at %02d:%02d past the hour", r.ByMinute[0], r.BySecond[0])
+		if hours != "" {
+			return clock + ", " + hours
+		}
+		return clock
+	}
+
+	parts := make([]string, 0, 3)
+	if hours != "" {
+		parts = append(parts, hours)
+	}
+	if len(r.ByMinute) > 0 {
+		parts = append(parts, describeUnit(r.ByMinute, Minute.valueRange(), "minute", "分", lang))
+	}
+	if len(r.BySecond) > 0 {
+		parts = append(parts, describeUnit(r.BySecond, Second.valueRange(), "second", "秒", lang))
+	}
+
+	if lang == Chinese {
+		return strings.Join(parts, "")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// 获取英文序数词，负数表示倒数，如 1st、last、2nd last
+func rruleNth(n int) string {
+	if n == -1 {
+		return "last"
+	}
+	if n < 0 {
+		return rruleNth(-n) + " last"
+	}
+
+	suffix := "th"
+	switch {
+	case n%100 >= 11 && n%100 <= 13:
+	case n%10 == 1:
+		suffix = "st"
+	case n%10 == 2:
+		suffix = "nd"
+	case n%10 == 3:
+		suffix = "rd"
+	}
+	return strconv.Itoa(n) + suffix
+}
+
+// 获取中文序数，负数表示倒数，如 第1天、倒数第2天
+func chineseNth(n int, unit string) string {
+	if n < 0 {
+		return fmt.Sprintf("倒数第%d%s", -n, unit)
+	}
+	return fmt.Sprintf("第%d%s", n, unit)
+}
+
+// 将 time.Weekday 转换为 ISO 8601 表示，星期一到星期天使用1-7表示
+func weekday2ISO(wd time.Weekday) int {
+	return (int(wd)+6)%7 + 1
+}
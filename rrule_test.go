@@ -0,0 +1,495 @@
+package beat
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRRuleParser(t *testing.T) {
+	const ny = "DTSTART;TZID=America/New_York:"
+
+	// RFC 5545 3.8.5.3 中的示例，"" 表示之后不再有有效时间
+	tests := []struct {
+		spec     string
+		expected []string
+	}{
+		{
+			// Daily for 10 occurrences
+			ny + "19970902T090000\nRRULE:FREQ=DAILY;COUNT=10",
+			[]string{
+				"1997-09-02T09:00:00-04:00", "1997-09-03T09:00:00-04:00", "1997-09-04T09:00:00-04:00",
+				"1997-09-05T09:00:00-04:00", "1997-09-06T09:00:00-04:00", "1997-09-07T09:00:00-04:00",
+				"1997-09-08T09:00:00-04:00", "1997-09-09T09:00:00-04:00", "1997-09-10T09:00:00-04:00",
+				"1997-09-11T09:00:00-04:00", "",
+			},
+		},
+		{
+			// Every other day - forever
+			ny + "19970902T090000\nRRULE:FREQ=DAILY;INTERVAL=2",
+			[]string{"1997-09-02T09:00:00-04:00", "1997-09-04T09:00:00-04:00", "1997-09-06T09:00:00-04:00"},
+		},
+		{
+			// Every 10 days, 5 occurrences
+			ny + "19970902T090000\nRRULE:FREQ=DAILY;INTERVAL=10;COUNT=5",
+			[]string{
+				"1997-09-02T09:00:00-04:00", "1997-09-12T09:00:00-04:00", "1997-09-22T09:00:00-04:00",
+				"1997-10-02T09:00:00-04:00", "1997-10-12T09:00:00-04:00", "",
+			},
+		},
+		{
+			// Every day in January, for 3 years
+			ny + "19980101T090000\nRRULE:FREQ=YEARLY;UNTIL=20000131T140000Z;BYMONTH=1;BYDAY=SU,MO,TU,WE,TH,FR,SA",
+			[]string{"1998-01-01T09:00:00-05:00", "1998-01-02T09:00:00-05:00", "1998-01-03T09:00:00-05:00"},
+		},
+		{
+			// Weekly for 10 occurrences
+			ny + "19970902T090000\nRRULE:FREQ=WEEKLY;COUNT=10",
+			[]string{
+				"1997-09-02T09:00:00-04:00", "1997-09-09T09:00:00-04:00", "1997-09-16T09:00:00-04:00",
+				"1997-09-23T09:00:00-04:00", "1997-09-30T09:00:00-04:00", "1997-10-07T09:00:00-04:00",
+				"1997-10-14T09:00:00-04:00", "1997-10-21T09:00:00-04:00", "1997-10-28T09:00:00-05:00",
+				"1997-11-04T09:00:00-05:00", "",
+			},
+		},
+		{
+			// Weekly on Tuesday and Thursday for five weeks
+			ny + "19970902T090000\nRRULE:FREQ=WEEKLY;UNTIL=19971007T000000Z;WKST=SU;BYDAY=TU,TH",
+			[]string{
+				"1997-09-02T09:00:00-04:00", "1997-09-04T09:00:00-04:00", "1997-09-09T09:00:00-04:00",
+				"1997-09-11T09:00:00-04:00", "1997-09-16T09:00:00-04:00", "1997-09-18T09:00:00-04:00",
+				"1997-09-23T09:00:00-04:00", "1997-09-25T09:00:00-04:00", "1997-09-30T09:00:00-04:00",
+				"1997-10-02T09:00:00-04:00", "",
+			},
+		},
+		{
+			// Every other week on Monday, Wednesday, and Friday until December 24, 1997
+			ny + "19970901T090000\nRRULE:FREQ=WEEKLY;INTERVAL=2;UNTIL=19971224T000000Z;WKST=SU;BYDAY=MO,WE,FR",
+			[]string{
+				"1997-09-01T09:00:00-04:00", "1997-09-03T09:00:00-04:00", "1997-09-05T09:00:00-04:00",
+				"1997-09-15T09:00:00-04:00", "1997-09-17T09:00:00-04:00", "1997-09-19T09:00:00-04:00",
+				"1997-09-29T09:00:00-04:00",
+			},
+		},
+		{
+			// Every other week on Tuesday and Thursday, for 8 occurrences
+			ny + "19970902T090000\nRRULE:FREQ=WEEKLY;INTERVAL=2;COUNT=8;WKST=SU;BYDAY=TU,TH",
+			[]string{
+				"1997-09-02T09:00:00-04:00", "1997-09-04T09:00:00-04:00", "1997-09-16T09:00:00-04:00",
+				"1997-09-18T09:00:00-04:00", "1997-09-30T09:00:00-04:00", "1997-10-02T09:00:00-04:00",
+				"1997-10-14T09:00:00-04:00", "1997-10-16T09:00:00-04:00", "",
+			},
+		},
+		{
+			// Monthly on the first Friday for 10 occurrences
+			ny + "19970905T090000\nRRULE:FREQ=MONTHLY;COUNT=10;BYDAY=1FR",
+			[]string{
+				"1997-09-05T09:00:00-04:00", "1997-10-03T09:00:00-04:00", "1997-11-07T09:00:00-05:00",
+				"1997-12-05T09:00:00-05:00", "1998-01-02T09:00:00-05:00", "1998-02-06T09:00:00-05:00",
+				"1998-03-06T09:00:00-05:00", "1998-04-03T09:00:00-05:00", "1998-05-01T09:00:00-04:00",
+				"1998-06-05T09:00:00-04:00", "",
+			},
+		},
+		{
+			// Every other month on the first and last Sunday of the month for 10 occurrences
+			ny + "19970907T090000\nRRULE:FREQ=MONTHLY;INTERVAL=2;COUNT=10;BYDAY=1SU,-1SU",
+			[]string{
+				"1997-09-07T09:00:00-04:00", "1997-09-28T09:00:00-04:00", "1997-11-02T09:00:00-05:00",
+				"1997-11-30T09:00:00-05:00", "1998-01-04T09:00:00-05:00", "1998-01-25T09:00:00-05:00",
+				"1998-03-01T09:00:00-05:00", "1998-03-29T09:00:00-05:00", "1998-05-03T09:00:00-04:00",
+				"1998-05-31T09:00:00-04:00", "",
+			},
+		},
+		{
+			// Monthly on the second-to-last Monday of the month for 6 months
+			ny + "19970922T090000\nRRULE:FREQ=MONTHLY;COUNT=6;BYDAY=-2MO",
+			[]string{
+				"1997-09-22T09:00:00-04:00", "1997-10-20T09:00:00-04:00", "1997-11-17T09:00:00-05:00",
+				"1997-12-22T09:00:00-05:00", "1998-01-19T09:00:00-05:00", "1998-02-16T09:00:00-05:00", "",
+			},
+		},
+		{
+			// Monthly on the third-to-the-last day of the month, forever
+			ny + "19970928T090000\nRRULE:FREQ=MONTHLY;BYMONTHDAY=-3",
+			[]string{
+				"1997-09-28T09:00:00-04:00", "1997-10-29T09:00:00-05:00", "1997-11-28T09:00:00-05:00",
+				"1997-12-29T09:00:00-05:00", "1998-01-29T09:00:00-05:00", "1998-02-26T09:00:00-05:00",
+			},
+		},
+		{
+			// Monthly on the first and last day of the month for 10 occurrences
+			ny + "19970930T090000\nRRULE:FREQ=MONTHLY;COUNT=10;BYMONTHDAY=1,-1",
+			[]string{
+				"1997-09-30T09:00:00-04:00", "1997-10-01T09:00:00-04:00", "1997-10-31T09:00:00-05:00",
+				"1997-11-01T09:00:00-05:00", "1997-11-30T09:00:00-05:00", "1997-12-01T09:00:00-05:00",
+				"1997-12-31T09:00:00-05:00", "1998-01-01T09:00:00-05:00", "1998-01-31T09:00:00-05:00",
+				"1998-02-01T09:00:00-05:00", "",
+			},
+		},
+		{
+			// Every 18 months on the 10th thru 15th of the month for 10 occurrences
+			ny + "19970910T090000\nRRULE:FREQ=MONTHLY;INTERVAL=18;COUNT=10;BYMONTHDAY=10,11,12,13,14,15",
+			[]string{
+				"1997-09-10T09:00:00-04:00", "1997-09-11T09:00:00-04:00", "1997-09-12T09:00:00-04:00",
+				"1997-09-13T09:00:00-04:00", "1997-09-14T09:00:00-04:00", "1997-09-15T09:00:00-04:00",
+				"1999-03-10T09:00:00-05:00", "1999-03-11T09:00:00-05:00", "1999-03-12T09:00:00-05:00",
+				"1999-03-13T09:00:00-05:00", "",
+			},
+		},
+		{
+			// Every Tuesday, every other month
+			ny + "19970902T090000\nRRULE:FREQ=MONTHLY;INTERVAL=2;BYDAY=TU",
+			[]string{
+				"1997-09-02T09:00:00-04:00", "1997-09-09T09:00:00-04:00", "1997-09-16T09:00:00-04:00",
+				"1997-09-23T09:00:00-04:00", "1997-09-30T09:00:00-04:00", "1997-11-04T09:00:00-05:00",
+			},
+		},
+		{
+			// Yearly in June and July for 10 occurrences
+			ny + "19970610T090000\nRRULE:FREQ=YEARLY;COUNT=10;BYMONTH=6,7",
+			[]string{
+				"1997-06-10T09:00:00-04:00", "1997-07-10T09:00:00-04:00", "1998-06-10T09:00:00-04:00",
+				"1998-07-10T09:00:00-04:00", "1999-06-10T09:00:00-04:00", "1999-07-10T09:00:00-04:00",
+				"2000-06-10T09:00:00-04:00", "2000-07-10T09:00:00-04:00", "2001-06-10T09:00:00-04:00",
+				"2001-07-10T09:00:00-04:00", "",
+			},
+		},
+		{
+			// Every other year on January, February, and March for 10 occurrences
+			ny + "19970310T090000\nRRULE:FREQ=YEARLY;INTERVAL=2;COUNT=10;BYMONTH=1,2,3",
+			[]string{
+				"1997-03-10T09:00:00-05:00", "1999-01-10T09:00:00-05:00", "1999-02-10T09:00:00-05:00",
+				"1999-03-10T09:00:00-05:00", "2001-01-10T09:00:00-05:00", "2001-02-10T09:00:00-05:00",
+				"2001-03-10T09:00:00-05:00", "2003-01-10T09:00:00-05:00", "2003-02-10T09:00:00-05:00",
+				"2003-03-10T09:00:00-05:00", "",
+			},
+		},
+		{
+			// Every third year on the 1st, 100th, and 200th day for 10 occurrences
+			ny + "19970101T090000\nRRULE:FREQ=YEARLY;INTERVAL=3;COUNT=10;BYYEARDAY=1,100,200",
+			[]string{
+				"1997-01-01T09:00:00-05:00", "1997-04-10T09:00:00-04:00", "1997-07-19T09:00:00-04:00",
+				"2000-01-01T09:00:00-05:00", "2000-04-09T09:00:00-04:00", "2000-07-18T09:00:00-04:00",
+				"2003-01-01T09:00:00-05:00", "2003-04-10T09:00:00-04:00", "2003-07-19T09:00:00-04:00",
+				"2006-01-01T09:00:00-05:00", "",
+			},
+		},
+		{
+			// Every 20th Monday of the year, forever
+			ny + "19970519T090000\nRRULE:FREQ=YEARLY;BYDAY=20MO",
+			[]string{"1997-05-19T09:00:00-04:00", "1998-05-18T09:00:00-04:00", "1999-05-17T09:00:00-04:00"},
+		},
+		{
+			// Monday of week number 20 (where the default start of the week is Monday), forever
+			ny + "19970512T090000\nRRULE:FREQ=YEARLY;BYWEEKNO=20;BYDAY=MO",
+			[]string{"1997-05-12T09:00:00-04:00", "1998-05-11T09:00:00-04:00", "1999-05-17T09:00:00-04:00"},
+		},
+		{
+			// Every Thursday in March, forever
+			ny + "19970313T090000\nRRULE:FREQ=YEARLY;BYMONTH=3;BYDAY=TH",
+			[]string{
+				"1997-03-13T09:00:00-05:00", "1997-03-20T09:00:00-05:00", "1997-03-27T09:00:00-05:00",
+				"1998-03-05T09:00:00-05:00", "1998-03-12T09:00:00-05:00",
+			},
+		},
+		{
+			// Every Friday the 13th, forever (DTSTART 不满足规则，不作为第一次执行)
+			ny + "19970902T090000\nRRULE:FREQ=MONTHLY;BYDAY=FR;BYMONTHDAY=13",
+			[]string{
+				"1998-02-13T09:00:00-05:00", "1998-03-13T09:00:00-05:00", "1998-11-13T09:00:00-05:00",
+				"1999-08-13T09:00:00-04:00", "2000-10-13T09:00:00-04:00",
+			},
+		},
+		{
+			// The first Saturday that follows the first Sunday of the month, forever
+			ny + "19970913T090000\nRRULE:FREQ=MONTHLY;BYDAY=SA;BYMONTHDAY=7,8,9,10,11,12,13",
+			[]string{
+				"1997-09-13T09:00:00-04:00", "1997-10-11T09:00:00-04:00", "1997-11-08T09:00:00-05:00",
+				"1997-12-13T09:00:00-05:00", "1998-01-10T09:00:00-05:00",
+			},
+		},
+		{
+			// U.S. Presidential Election Day, every 4 years
+			ny + "19961105T090000\nRRULE:FREQ=YEARLY;INTERVAL=4;BYMONTH=11;BYDAY=TU;BYMONTHDAY=2,3,4,5,6,7,8",
+			[]string{"1996-11-05T09:00:00-05:00", "2000-11-07T09:00:00-05:00", "2004-11-02T09:00:00-05:00"},
+		},
+		{
+			// The third instance into the month of one of Tuesday, Wednesday, or Thursday, for the next 3 months
+			ny + "19970904T090000\nRRULE:FREQ=MONTHLY;COUNT=3;BYDAY=TU,WE,TH;BYSETPOS=3",
+			[]string{"1997-09-04T09:00:00-04:00", "1997-10-07T09:00:00-04:00", "1997-11-06T09:00:00-05:00", ""},
+		},
+		{
+			// The second-to-last weekday of the month
+			ny + "19970929T090000\nRRULE:FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-2",
+			[]string{
+				"1997-09-29T09:00:00-04:00", "1997-10-30T09:00:00-05:00", "1997-11-27T09:00:00-05:00",
+				"1997-12-30T09:00:00-05:00", "1998-01-29T09:00:00-05:00", "1998-02-26T09:00:00-05:00",
+			},
+		},
+		{
+			// Every 15 minutes for 6 occurrences
+			ny + "19970902T090000\nRRULE:FREQ=MINUTELY;INTERVAL=15;COUNT=6",
+			[]string{
+				"1997-09-02T09:00:00-04:00", "1997-09-02T09:15:00-04:00", "1997-09-02T09:30:00-04:00",
+				"1997-09-02T09:45:00-04:00", "1997-09-02T10:00:00-04:00", "1997-09-02T10:15:00-04:00", "",
+			},
+		},
+		{
+			// Every hour and a half for 4 occurrences
+			ny + "19970902T090000\nRRULE:FREQ=MINUTELY;INTERVAL=90;COUNT=4",
+			[]string{
+				"1997-09-02T09:00:00-04:00", "1997-09-02T10:30:00-04:00", "1997-09-02T12:00:00-04:00",
+				"1997-09-02T13:30:00-04:00", "",
+			},
+		},
+		{
+			// Every 20 minutes from 9:00 AM to 4:40 PM every day
+			ny + "19970902T090000\nRRULE:FREQ=DAILY;BYHOUR=9,10,11,12,13,14,15,16;BYMINUTE=0,20,40",
+			[]string{"1997-09-02T09:00:00-04:00", "1997-09-02T09:20:00-04:00", "1997-09-02T09:40:00-04:00"},
+		},
+		{
+			ny + "19970902T090000\nRRULE:FREQ=MINUTELY;INTERVAL=20;BYHOUR=9,10,11,12,13,14,15,16",
+			[]string{"1997-09-02T09:00:00-04:00", "1997-09-02T09:20:00-04:00", "1997-09-02T09:40:00-04:00"},
+		},
+		{
+			// WKST 的影响
+			ny + "19970805T090000\nRRULE:FREQ=WEEKLY;INTERVAL=2;COUNT=4;BYDAY=TU,SU;WKST=MO",
+			[]string{
+				"1997-08-05T09:00:00-04:00", "1997-08-10T09:00:00-04:00", "1997-08-19T09:00:00-04:00",
+				"1997-08-24T09:00:00-04:00", "",
+			},
+		},
+		{
+			ny + "19970805T090000\nRRULE:FREQ=WEEKLY;INTERVAL=2;COUNT=4;BYDAY=TU,SU;WKST=SU",
+			[]string{
+				"1997-08-05T09:00:00-04:00", "1997-08-17T09:00:00-04:00", "1997-08-19T09:00:00-04:00",
+				"1997-08-31T09:00:00-04:00", "",
+			},
+		},
+		{
+			// 无效的日期 (如 2 月 30 日) 将被忽略
+			ny + "20070115T090000\nRRULE:FREQ=MONTHLY;BYMONTHDAY=15,30;COUNT=5",
+			[]string{
+				"2007-01-15T09:00:00-05:00", "2007-01-30T09:00:00-05:00", "2007-02-15T09:00:00-05:00",
+				"2007-03-15T09:00:00-04:00", "2007-03-30T09:00:00-04:00", "",
+			},
+		},
+		{
+			// 单行表达式
+			"DTSTART:20260101T000000Z RRULE:FREQ=MONTHLY;BYDAY=2TU;BYHOUR=9",
+			[]string{"2026-01-13T09:00:00Z", "2026-02-10T09:00:00Z", "2026-03-10T09:00:00Z"},
+		},
+	}
+
+	for _, test := range tests {
+		sched, err := NewRRuleParser().Parse(test.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		rrule := sched.(*SchedRRule)
+		now := parseTime("1990-01-01T00:00:00Z")
+
+		for i, expected := range test.expected {
+			next := rrule.Next(now)
+			if !next.Equal(parseTime(expected)) {
+				t.Errorf("Fail evaluating %q on %s: (expected) %s != %s (actual)", test.spec, now, expected, next)
+				break
+			}
+			if next.IsZero() {
+				break
+			}
+
+			if i > 0 {
+				if prev := rrule.Prev(next); !prev.Equal(now) {
+					t.Errorf("Fail reversing %q on %s: (expected) %s != %s (actual)", test.spec, next, now, prev)
+				}
+			}
+			now = next
+		}
+	}
+}
+
+func TestRRuleSchedule(t *testing.T) {
+	parser := NewRRuleParser(WithDefaultLocation(time.UTC))
+
+	tests := []struct {
+		spec string
+		time string
+		next string
+		prev string
+	}{
+		{
+			"DTSTART;TZID=America/New_York:19970902T090000\nRRULE:FREQ=DAILY;BYHOUR=9,10,11,12,13,14,15,16;BYMINUTE=0,20,40",
+			"1997-09-02T20:40:00Z", "1997-09-03T13:00:00Z", "1997-09-02T20:20:00Z",
+		},
+		{
+			"DTSTART;TZID=America/New_York:19970902T090000\nRRULE:FREQ=MINUTELY;INTERVAL=20;BYHOUR=9,10,11,12,13,14,15,16",
+			"1997-09-02T20:40:00Z", "1997-09-03T13:00:00Z", "1997-09-02T20:20:00Z",
+		},
+		{
+			// Daily until December 24, 1997
+			"DTSTART;TZID=America/New_York:19970902T090000\nRRULE:FREQ=DAILY;UNTIL=19971224T000000Z",
+			"1997-12-23T14:00:00Z", "", "1997-12-22T14:00:00Z",
+		},
+		{
+			"DTSTART;TZID=America/New_York:19970902T090000\nRRULE:FREQ=DAILY;UNTIL=19971224T000000Z",
+			"2026-01-01T00:00:00Z", "", "1997-12-23T14:00:00Z",
+		},
+		{
+			// UNTIL 仅有日期时包含当天
+			"DTSTART:19970902T090000\nRRULE:FREQ=DAILY;UNTIL=19971224",
+			"2026-01-01T00:00:00Z", "", "1997-12-24T09:00:00Z",
+		},
+		{
+			// 远离 DTSTART 时直接定位到所在周期
+			"DTSTART:20000101T000000Z RRULE:FREQ=SECONDLY;INTERVAL=7;BYHOUR=3",
+			"2026-06-15T12:00:00Z", "2026-06-16T03:00:04Z", "2026-06-15T03:59:54Z",
+		},
+		{
+			"DTSTART:20000105T093000Z RRULE:FREQ=YEARLY",
+			"2026-06-15T12:00:00Z", "2027-01-05T09:30:00Z", "2026-01-05T09:30:00Z",
+		},
+		{
+			"DTSTART:20000105T093000Z RRULE:FREQ=WEEKLY;COUNT=2",
+			"2000-01-05T09:30:00Z", "2000-01-12T09:30:00Z", "",
+		},
+		{
+			// 无 BYxxx 规则时 COUNT 的截止时间在解析时确定
+			"DTSTART:20100101T000000Z RRULE:FREQ=MINUTELY;COUNT=100000000",
+			"2026-06-15T12:00:30Z", "2026-06-15T12:01:00Z", "2026-06-15T12:00:00Z",
+		},
+		{
+			"DTSTART:20100101T000000Z RRULE:FREQ=MINUTELY;COUNT=1000000",
+			"2026-06-15T12:00:30Z", "", "2011-11-26T10:39:00Z",
+		},
+		{
+			"DTSTART:20100101T000000Z RRULE:FREQ=DAILY;BYHOUR=0,12;COUNT=100000",
+			"2026-06-15T12:00:30Z", "2026-06-16T00:00:00Z", "2026-06-15T12:00:00Z",
+		},
+		{
+			"DTSTART:20100101T000000Z RRULE:FREQ=DAILY;BYHOUR=0,12;COUNT=5000",
+			"2026-06-15T12:00:30Z", "", "2016-11-04T12:00:00Z",
+		},
+	}
+
+	for _, test := range tests {
+		sched, err := parser.Parse(test.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		now := parseTime(test.time)
+		if actual := sched.Next(now); !actual.Equal(parseTime(test.next)) {
+			t.Errorf("Fail evaluating %q on %s: (expected) %s != %s (actual)", test.spec, test.time, test.next, actual)
+		}
+		if actual := sched.(ReversibleSchedule).Prev(now); !actual.Equal(parseTime(test.prev)) {
+			t.Errorf("Fail reversing %q on %s: (expected) %s != %s (actual)", test.spec, test.time, test.prev, actual)
+		}
+	}
+
+	// 缺少 DTSTART 时从解析当天的零点开始
+	sched, err := parser.Parse("FREQ=DAILY;BYHOUR=9;BYMINUTE=30")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC()
+	if next := sched.Next(now); next.Hour() != 9 || next.Minute() != 30 || next.Second() != 0 || next.Sub(now) > 24*time.Hour {
+		t.Errorf("unexpected next time %s", next)
+	}
+
+	// 缺少 DTSTART 时日由规则指定，与解析的日期无关
+	sched, err = parser.Parse("FREQ=WEEKLY;BYDAY=MO")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next := sched.Next(now); next.Weekday() != time.Monday || next.Hour() != 0 || next.Sub(now) > 7*24*time.Hour {
+		t.Errorf("unexpected next time %s", next)
+	}
+
+	sched, err = parser.Parse("FREQ=MONTHLY;BYMONTHDAY=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next := sched.Next(now); next.Day() != 1 || next.Hour() != 0 || next.Sub(now) > 31*24*time.Hour {
+		t.Errorf("unexpected next time %s", next)
+	}
+}
+
+func TestRRuleCount(t *testing.T) {
+	// 连续求值时复用已统计的次数，回退后重新统计
+	sched, err := NewRRuleParser().Parse("DTSTART:20100101T000000Z RRULE:FREQ=DAILY;BYHOUR=0,12;COUNT=100000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rrule := sched.(*SchedRRule)
+
+	now := parseTime("2026-06-15T00:00:00Z")
+	for i := 0; i < 100; i++ {
+		next := rrule.Next(now)
+		if expected := now.Add(12 * time.Hour); !next.Equal(expected) {
+			t.Fatalf("Fail evaluating on %s: (expected) %s != %s (actual)", now, expected, next)
+		}
+		if prev := rrule.Prev(next); !prev.Equal(now) {
+			t.Fatalf("Fail reversing on %s: (expected) %s != %s (actual)", next, now, prev)
+		}
+		now = next
+	}
+
+	if prev := rrule.Prev(parseTime("2010-01-01T12:00:00Z")); !prev.Equal(parseTime("2010-01-01T00:00:00Z")) {
+		t.Errorf("unexpected prev time %s", prev)
+	}
+	if last := rrule.Prev(parseTime("2300-01-01T00:00:00Z")); !last.Equal(parseTime("2146-11-23T12:00:00Z")) {
+		t.Errorf("unexpected last time %s", last)
+	}
+}
+
+func TestRRuleParseError(t *testing.T) {
+	tests := []struct {
+		spec   string
+		reason ParseReason
+		offset int
+	}{
+		{"RRULE:FREQ=SOMETIMES", ReasonSyntax, 11},
+		{"RRULE:INTERVAL=2", ReasonSyntax, 6},
+		{"RRULE:FREQ=DAILY;BYHOUR=9,25", ReasonOutOfRange, 26},
+		{"RRULE:FREQ=DAILY;BYHOUR=-1", ReasonOutOfRange, 24},
+		{"RRULE:FREQ=DAILY;INTERVAL=0", ReasonOutOfRange, 26},
+		{"RRULE:FREQ=DAILY;FREQ=WEEKLY", ReasonSyntax, 17},
+		{"RRULE:FREQ=DAILY;BYEASTER=1", ReasonSyntax, 17},
+		{"RRULE:FREQ=DAILY;COUNT=3;UNTIL=19970101", ReasonSyntax, 25},
+		{"RRULE:FREQ=WEEKLY;BYDAY=1MO", ReasonSyntax, 18},
+		{"RRULE:FREQ=MONTHLY;BYDAY=MO,1XX", ReasonSyntax, 29},
+		{"RRULE:FREQ=MONTHLY;BYWEEKNO=1", ReasonSyntax, 19},
+		{"RRULE:FREQ=MONTHLY;BYSETPOS=1", ReasonSyntax, 19},
+		{"DTSTART;TZID=Nowhere/City:19970902T090000 RRULE:FREQ=DAILY", ReasonLocation, 13},
+		{"DTSTART:1997 RRULE:FREQ=DAILY", ReasonSyntax, 8},
+		{"RRULE:FREQ=DAILY RRULE:FREQ=WEEKLY", ReasonSyntax, 17},
+		{"EXDATE:19970902T090000 RRULE:FREQ=DAILY", ReasonSyntax, 0},
+		{"DTSTART:19970902T090000", ReasonSyntax, 23},
+		{"DTSTART:19970902T090000Z RRULE:FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30", ReasonNeverFire, 31},
+		{"DTSTART:19970902T090000Z RRULE:FREQ=DAILY;UNTIL=19970901T000000Z", ReasonNeverFire, 31},
+		{"RRULE:FREQ=DAILY;INTERVAL=2", ReasonSyntax, 17},
+		{"RRULE:FREQ=DAILY;COUNT=3", ReasonSyntax, 17},
+		{"RRULE:FREQ=MONTHLY;BYDAY=MO;BYSETPOS=1", ReasonSyntax, 28},
+		{"RRULE:FREQ=WEEKLY", ReasonSyntax, 6},
+		{"RRULE:FREQ=MONTHLY;BYHOUR=9", ReasonSyntax, 6},
+		{"RRULE:FREQ=YEARLY;BYMONTH=3", ReasonSyntax, 6},
+		{"RRULE:FREQ=YEARLY;BYWEEKNO=10", ReasonSyntax, 6},
+	}
+
+	for _, test := range tests {
+		_, err := NewRRuleParser().Parse(test.spec)
+
+		var pe *ParseError
+		if !errors.As(err, &pe) || pe.Reason != test.reason || pe.Offset != test.offset || pe.Expr != test.spec {
+			t.Errorf("unexpected error for %s: %v", test.spec, err)
+			continue
+		}
+		if test.reason == ReasonNeverFire && !errors.Is(err, ErrNeverFire) {
+			t.Errorf("expected ErrNeverFire for %s", test.spec)
+		}
+	}
+}
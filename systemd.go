@@ -0,0 +1,309 @@
+package beat
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// systemd 的星期名称，不区分大小写，星期一到星期天使用1-7表示
+var systemdDowNames = map[string]int{
+	"mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6, "sun": 7,
+	"monday": 1, "tuesday": 2, "wednesday": 3, "thursday": 4, "friday": 5, "saturday": 6, "sunday": 7,
+}
+
+// systemd 的预定义表达式
+var systemdShorthands = map[string]string{
+	"minutely":     "*-*-* *:*:00",
+	"hourly":       "*-*-* *:00:00",
+	"daily":        "*-*-* 00:00:00",
+	"weekly":       "Mon *-*-* 00:00:00",
+	"monthly":      "*-*-01 00:00:00",
+	"quarterly":    "*-01,04,07,10-01 00:00:00",
+	"semiannually": "*-01,07-01 00:00:00",
+	"yearly":       "*-01-01 00:00:00",
+	"annually":     "*-01-01 00:00:00",
+}
+
+// 兼容 systemd OnCalendar 的解析器
+//
+// 支持以下形式的表达式：
+//
+//	[weekdays] [[year-]month-day] [hour:minute[:second]] [timezone]
+//
+// 星期支持英文缩写或全称，如 Mon..Fri、Sat,Sun；年、月、日、时、分、秒支持 *、
+// 以逗号分隔的列表、范围 a..b 及重复 a/n (从 a 开始每隔 n)；日之前使用 ~ 代替 - 时
+// 表示倒数第几天，如 *-02~01 表示 2 月最后一天，Mon *-05~07/1 表示 5 月最后一个周一。
+// 省略的日期为 *-*-*，省略的时间为 00:00:00，未指定时区时使用缺省时区。
+// 同时支持 minutely、hourly、daily、weekly、monthly、quarterly、semiannually、
+// yearly (annually) 等预定义表达式
+type SystemdParser struct {
+	location *time.Location // 缺省时区
+}
+
+// 创建 systemd OnCalendar 解析器，opts 中仅 WithDefaultLocation 有效
+func NewSystemdParser(opts ...parserOption) *SystemdParser {
+	return &SystemdParser{location: NewParser(opts...).defaultLoction}
+}
+
+// 解析 OnCalendar 表达式
+func (p *SystemdParser) Parse(exp string) (Schedule, error) {
+	fields, offsets := splitFields(exp)
+	if len(fields) == 0 {
+		return nil, locateError(newParseError(ReasonFieldCount, 0, "empty expression"), exp, 0, -1, 0)
+	}
+
+	// 预定义表达式展开后的各部分使用其在表达式中的偏移
+	if expanded, found := systemdShorthands[strings.ToLower(fields[0])]; found {
+		parts := strings.Fields(expanded)
+		fields = append(parts, fields[1:]...)
+		offsets = append(slices.Repeat([]int{offsets[0]}, len(parts)), offsets[1:]...)
+	}
+
+	st := &SchedTime{
+		Month:    Month.all(),
+		Dom:      Dom.all(),
+		Dow:      Dow.all(),
+		Hour:     1,
+		Minute:   1,
+		Second:   1,
+		location: p.location,
+	}
+
+	i := 0
+	if i < len(fields) && isSystemdWeekdays(fields[i]) {
+		if err := parseSystemdWeekdays(fields[i], st); err != nil {
+			return nil, locateError(err, exp, Dow, -1, offsets[i])
+		}
+		i++
+	}
+
+	if i < len(fields) && !strings.Contains(fields[i], ":") && strings.ContainsAny(fields[i], "-~") {
+		if err := parseSystemdDate(fields[i], st); err != nil {
+			return nil, locateError(err, exp, 0, -1, offsets[i])
+		}
+		i++
+	}
+
+	if i < len(fields) && strings.Contains(fields[i], ":") {
+		if err := parseSystemdTime(fields[i], st); err != nil {
+			return nil, locateError(err, exp, 0, -1, offsets[i])
+		}
+		i++
+	}
+
+	switch {
+	case i == len(fields)-1:
+		location, err := time.LoadLocation(fields[i])
+		if err != nil {
+			pe := newParseError(ReasonLocation, 0, "bad location '%s': %v", fields[i], err)
+			return nil, locateError(pe, exp, 0, -1, offsets[i])
+		}
+		st.location = location
+
+	case i < len(fields):
+		pe := newParseError(ReasonSyntax, 0, "unexpected '%s'", fields[i])
+		return nil, locateError(pe, exp, 0, -1, offsets[i])
+	}
+
+	if len(st.Year) > 0 {
+		st.layout = append(slices.Clone(DefaultLayout), Year)
+	}
+
+	if err := st.Validate(); errors.Is(err, ErrNeverFire) {
+		pe := newParseError(ReasonNeverFire, 0, "%s", err)
+		pe.Err = err
+		return nil, locateError(pe, exp, 0, -1, 0)
+	}
+
+	return st, nil
+}
+
+// 判断是否为星期部分，即以字母开头
+func isSystemdWeekdays(field string) bool {
+	return field[0] >= 'A' && field[0] <= 'Z' || field[0] >= 'a' && field[0] <= 'z'
+}
+
+// 解析星期，如 Mon..Fri,Sun，范围可以跨过周末，如 Sat..Mon
+func parseSystemdWeekdays(field string, st *SchedTime) error {
+	st.Dow = 0
+
+	pos := 0
+	for _, item := range strings.Split(field, ",") {
+		low, high, found := strings.Cut(item, "..")
+		if !found {
+			low, high, found = strings.Cut(item, "-")
+		}
+
+		start, ok := systemdDowNames[strings.ToLower(low)]
+		if !ok {
+			return newParseError(ReasonSyntax, pos, "unknown weekday '%s'", low)
+		}
+
+		end := start
+		if found {
+			end, ok = systemdDowNames[strings.ToLower(high)]
+			if !ok {
+				return newParseError(ReasonSyntax, pos+len(item)-len(high), "unknown weekday '%s'", high)
+			}
+		}
+
+		for dow := start; ; dow = dow%7 + 1 {
+			st.Dow |= 1 << dow
+			if dow == end {
+				break
+			}
+		}
+
+		pos += len(item) + 1
+	}
+
+	return nil
+}
+
+// 解析日期，如 *-*-01、2026-01..06-15、*-02~01
+func parseSystemdDate(field string, st *SchedTime) error {
+	sep := strings.LastIndexAny(field, "-~")
+	if strings.Contains(field, "~") {
+		sep = strings.Index(field, "~")
+	}
+	if sep < 0 {
+		return newParseError(ReasonSyntax, 0, "bad date '%s'", field)
+	}
+
+	ym, day := field[:sep], field[sep+1:]
+	parts := strings.Split(ym, "-")
+	if len(parts) > 2 {
+		return newParseError(ReasonSyntax, len(parts[0])+1+len(parts[1]), "bad date '%s'", field)
+	}
+
+	pos := 0
+	if len(parts) == 2 {
+		if parts[0] != "*" {
+			min, max := Year.Bounds()
+			err := parseSystemdValues(parts[0], min, max, false, func(v int) {
+				st.Year = append(st.Year, v)
+			})
+			if err != nil {
+				return err
+			}
+			slices.Sort(st.Year)
+			st.Year = slices.Compact(st.Year)
+		}
+		pos += len(parts[0]) + 1
+	}
+
+	month := parts[len(parts)-1]
+	st.Month = 0
+	err := parseSystemdValues(month, 1, 12, false, func(v int) {
+		st.Month |= 1 << v
+	})
+	if err != nil {
+		return shiftError(err, pos)
+	}
+
+	if field[sep] == '~' {
+		// ~n 表示倒数第 n 天，即 L-(n-1)，重复时向月末方向递减
+		st.Dom = 0
+		err = parseSystemdValues(day, 1, 31, true, func(v int) {
+			st.LastDays |= 1 << (v - 1)
+		})
+	} else {
+		st.Dom = 0
+		err = parseSystemdValues(day, 1, 31, false, func(v int) {
+			st.Dom |= 1 << v
+		})
+	}
+
+	return shiftError(err, sep+1)
+}
+
+// 解析时间，如 09:00、*:0/15、08..18:00:30
+func parseSystemdTime(field string, st *SchedTime) error {
+	parts := strings.Split(field, ":")
+	if len(parts) > 3 {
+		return newParseError(ReasonSyntax, len(parts[0])+len(parts[1])+len(parts[2])+2, "bad time '%s'", field)
+	}
+
+	targets := []*uint64{&st.Hour, &st.Minute, &st.Second}
+	fields := []LayoutField{Hour, Minute, Second}
+
+	pos := 0
+	for i, part := range parts {
+		bits := uint64(0)
+		min, max := fields[i].Bounds()
+		err := parseSystemdValues(part, min, max, false, func(v int) {
+			bits |= 1 << v
+		})
+		if err != nil {
+			return shiftError(err, pos)
+		}
+
+		*targets[i] = bits
+		pos += len(part) + 1
+	}
+
+	return nil
+}
+
+// 解析日期或时间中的一个部分，支持 *、a、a..b、a/n、a..b/n 及以逗号分隔的列表
+//
+// down 为 true 时，a/n 从 a 开始向最小值方向重复，用于倒数的日
+func parseSystemdValues(field string, min, max int, down bool, set func(int)) error {
+	pos := 0
+	for _, item := range strings.Split(field, ",") {
+		rng, stepValue, hasStep := strings.Cut(item, "/")
+
+		start, end := min, max
+		if rng != "*" {
+			low, high, isRange := strings.Cut(rng, "..")
+
+			v, err := strconv.Atoi(low)
+			if err != nil {
+				return newParseError(ReasonSyntax, pos, "bad value '%s'", low)
+			}
+			start, end = v, v
+
+			switch {
+			case isRange:
+				end, err = strconv.Atoi(high)
+				if err != nil {
+					return newParseError(ReasonSyntax, pos+len(low)+2, "bad value '%s'", high)
+				}
+			case hasStep && down:
+				start, end = min, v
+			case hasStep:
+				end = max
+			}
+		}
+
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepValue)
+			if err != nil || step <= 0 {
+				return newParseError(ReasonStep, pos+len(rng)+1, "bad repetition '%s'", stepValue)
+			}
+		}
+
+		if start < min || end > max || start > end {
+			return newParseError(ReasonOutOfRange, pos, "out of range: %s", item)
+		}
+
+		if down && hasStep && rng != "*" {
+			for v := end; v >= start; v -= step {
+				set(v)
+			}
+		} else {
+			for v := start; v <= end; v += step {
+				set(v)
+			}
+		}
+
+		pos += len(item) + 1
+	}
+
+	return nil
+}
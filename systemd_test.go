@@ -0,0 +1,92 @@
+package beat
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSystemdParser(t *testing.T) {
+	tests := []struct {
+		spec     string
+		time     string
+		expected string
+	}{
+		// 2024-03-01 为星期五
+		{"Mon..Fri *-*-* 09:00:00 UTC", "2024-03-01T09:00:00Z", "2024-03-04T09:00:00Z"},
+		{"Sat,Sun 10:30 UTC", "2024-03-01T00:00:00Z", "2024-03-02T10:30:00Z"},
+		{"Fri..Mon 12:00 UTC", "2024-03-04T12:00:00Z", "2024-03-08T12:00:00Z"},
+		{"*-*-01 04:00 UTC", "2024-03-01T04:00:00Z", "2024-04-01T04:00:00Z"},
+		{"monthly UTC", "2024-03-15T00:00:00Z", "2024-04-01T00:00:00Z"},
+		{"weekly UTC", "2024-03-01T00:00:00Z", "2024-03-04T00:00:00Z"},
+		{"daily UTC", "2024-03-01T00:00:00Z", "2024-03-02T00:00:00Z"},
+		{"hourly UTC", "2024-03-01T00:00:00Z", "2024-03-01T01:00:00Z"},
+		{"minutely UTC", "2024-03-01T00:00:30Z", "2024-03-01T00:01:00Z"},
+		{"quarterly UTC", "2024-03-01T00:00:00Z", "2024-04-01T00:00:00Z"},
+		{"semiannually UTC", "2024-03-01T00:00:00Z", "2024-07-01T00:00:00Z"},
+		{"yearly UTC", "2024-03-01T00:00:00Z", "2025-01-01T00:00:00Z"},
+		{"*:0/15 UTC", "2024-03-01T00:50:00Z", "2024-03-01T01:00:00Z"},
+		{"*-*-* 08..18/2:00 UTC", "2024-03-01T09:00:00Z", "2024-03-01T10:00:00Z"},
+		{"*-*-* *:*:10,40 UTC", "2024-03-01T00:00:10Z", "2024-03-01T00:00:40Z"},
+		{"2025-*-* 00:00 UTC", "2024-03-01T00:00:00Z", "2025-01-01T00:00:00Z"},
+		{"2024..2025-02-29 UTC", "2024-03-01T00:00:00Z", ""},
+		{"03-15 06:00 UTC", "2024-03-01T00:00:00Z", "2024-03-15T06:00:00Z"},
+		{"*-1/2-1 UTC", "2024-03-01T00:00:00Z", "2024-05-01T00:00:00Z"},
+		{"*-02~01 UTC", "2024-01-01T00:00:00Z", "2024-02-29T00:00:00Z"},
+		{"*-02~03 UTC", "2025-01-01T00:00:00Z", "2025-02-26T00:00:00Z"},
+		{"Mon *-05~07/1 UTC", "2024-01-01T00:00:00Z", "2024-05-27T00:00:00Z"},
+		{"Monday *-*-* 09:00 Asia/Shanghai", "2024-03-01T00:00:00Z", "2024-03-04T01:00:00Z"},
+		{"Tue", "2024-03-01T00:00:00Z", "2024-03-05T00:00:00+08:00"},
+	}
+
+	parser := NewSystemdParser(WithDefaultLocation(time.FixedZone("CST", 8*3600)))
+
+	for _, test := range tests {
+		sched, err := parser.Parse(test.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		expected := parseTime(test.expected)
+		if actual := sched.Next(parseTime(test.time)); !actual.Equal(expected) {
+			t.Errorf("Fail evaluating %s on %s: (expected) %s != %s (actual)", test.spec, test.time, expected, actual)
+		}
+	}
+
+	sched, err := parser.Parse("Mon..Fri *-*-* 09:00:00 UTC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual := Describe(sched, English); actual != "At 09:00:00, Monday through Friday (UTC)" {
+		t.Errorf("unexpected description %s", actual)
+	}
+}
+
+func TestSystemdParseError(t *testing.T) {
+	tests := []struct {
+		spec   string
+		reason ParseReason
+		offset int
+	}{
+		{"", ReasonFieldCount, 0},
+		{"Mon..Fry 09:00", ReasonSyntax, 5},
+		{"Mon *-13-01", ReasonOutOfRange, 6},
+		{"*-*-1/0", ReasonStep, 6},
+		{"*-*-* 9:x", ReasonSyntax, 8},
+		{"*-*-* 24:00", ReasonOutOfRange, 6},
+		{"*-*-* 00:00 Nowhere/City", ReasonLocation, 12},
+		{"*-*-* 00:00 UTC extra", ReasonSyntax, 12},
+		{"*-02-30", ReasonNeverFire, 0},
+		{"daily Nowhere/City", ReasonLocation, 6},
+	}
+
+	for _, test := range tests {
+		_, err := NewSystemdParser().Parse(test.spec)
+
+		var pe *ParseError
+		if !errors.As(err, &pe) || pe.Reason != test.reason || pe.Offset != test.offset || pe.Expr != test.spec {
+			t.Errorf("unexpected error for %q: %v", test.spec, err)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package beat
+
+import "time"
+
+// 公历每 400 年循环一次，日期与星期的全部组合都会在一个周期内出现
+const gregorianCycle = 400
+
+// 检查定时是否会执行
+//
+// 永远不会执行时返回 ErrNeverFire，如 2 月 30 日；
+// 存在整年都不会执行的年份时返回 ErrRareFire，如 2 月 29 日且为星期一。
+// 指定了“年”域时仅检查指定的年份
+func (st *SchedTime) Validate() error {
+	if st.Month == 0 || st.Hour == 0 || st.Minute == 0 || st.Second == 0 {
+		return ErrNeverFire
+	}
+
+	years := st.Year
+	switch {
+	case len(years) > 0:
+	case st.Dow == Dow.all():
+		// 不限制星期时，是否匹配仅与是否为闰年有关
+		years = []int{2000, 2001}
+	default:
+		years = make([]int, gregorianCycle)
+		for i := range years {
+			years[i] = 2000 + i
+		}
+	}
+
+	missed := 0
+	for _, year := range years {
+		if !st.hasDayIn(year) {
+			missed++
+		}
+	}
+
+	switch {
+	case missed == len(years):
+		return ErrNeverFire
+	case missed > 0:
+		return ErrRareFire
+	}
+
+	return nil
+}
+
+// 判断指定年份中是否存在匹配的日期
+func (st *SchedTime) hasDayIn(year int) bool {
+	for month := time.January; month <= time.December; month++ {
+		if (1<<month)&st.Month != 0 && st.dayMask(year, month) != 0 {
+			return true
+		}
+	}
+
+	return false
+}